@@ -1,19 +1,25 @@
 package main
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "log"
     "os"
+    "strconv"
+    "time"
 
     "library-project/config"
 	_ "library-project/docs"
     "library-project/internal/handler"
+    "library-project/internal/migrate"
     "library-project/internal/middleware"
     "library-project/internal/models"
     "library-project/internal/repository"
     "library-project/internal/service"
+    "library-project/internal/storage"
 	"library-project/internal/utils"
+    "library-project/migrations"
 
     "github.com/gin-gonic/gin"
 	"github.com/google/uuid" 
@@ -50,29 +56,63 @@ func main() {
     }
     defer db.Close()
 
-	createSuperAdmin(db)
-
     if err := db.Ping(); err != nil {
         log.Fatalf("Failed to ping database: %v", err)
     }
 
-    if err := os.MkdirAll(cfg.Upload.Path, os.ModePerm); err != nil {
-        log.Fatalf("Failed to create upload directory: %v", err)
+    migrator, err := migrate.New(db, migrations.FS)
+    if err != nil {
+        log.Fatalf("Failed to load migrations: %v", err)
+    }
+
+    // `main migrate up|down [n]|status` manages the schema and exits
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrateCommand(migrator, os.Args[2:]); err != nil {
+            log.Fatalf("Migration failed: %v", err)
+        }
+        return
+    }
+
+    if cfg.Database.AutoMigrate {
+        applied, err := migrator.Up(context.Background())
+        if err != nil {
+            log.Fatalf("Failed to apply migrations: %v", err)
+        }
+        logger.WithField("applied", len(applied)).Info("Database migrations up to date")
+    }
+
+	createSuperAdmin(context.Background(), db)
+
+    files, err := storage.New(cfg.Upload)
+    if err != nil {
+        log.Fatalf("Failed to set up file storage: %v", err)
     }
 
     userRepo := repository.NewUserRepository(db)
     bookRepo := repository.NewBookRepository(db)
     categoryRepo := repository.NewCategoryRepository(db)
+    authorRepo := repository.NewAuthorRepository(db)
+    tagRepo := repository.NewTagRepository(db)
     commentRepo := repository.NewCommentRepository(db)
     likeRepo := repository.NewLikeRepository(db)
     savedRepo := repository.NewSavedBookRepository(db)
     refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+    downloadRepo := repository.NewDownloadRepository(db)
+    commentLikeRepo := repository.NewCommentLikeRepository(db)
+    readingRepo := repository.NewReadingRepository(db)
+    bookFileRepo := repository.NewBookFileRepository(db)
+    statsRepo := repository.NewStatisticsRepository(db)
+    unitOfWork := repository.NewUnitOfWork(db)
 
     authService := service.NewAuthService(userRepo, refreshTokenRepo, cfg)
-    bookService := service.NewBookService(bookRepo, categoryRepo, likeRepo, savedRepo, commentRepo)
+    bookService := service.NewBookService(bookRepo, categoryRepo, authorRepo, tagRepo, likeRepo, savedRepo, commentRepo, downloadRepo, commentLikeRepo, readingRepo, bookFileRepo, userRepo, unitOfWork, files, cfg)
+    statsService := service.NewStatisticsService(statsRepo, bookRepo)
+
+    go runUploadMaintenance(bookService, cfg.Upload)
 
     authHandler := handler.NewAuthHandler(authService)
-    bookHandler := handler.NewBookHandler(bookService, cfg)
+    bookHandler := handler.NewBookHandler(bookService, files, cfg)
+    statsHandler := handler.NewStatisticsHandler(statsService)
 
     // Create Gin router without default middleware
     r := gin.New()
@@ -81,6 +121,8 @@ func main() {
     r.Use(gin.Recovery()) // Recovery middleware
 
     r.Use(middleware.CORSMiddleware())
+    // Handlers bound their database work with middleware.QueryContext
+    r.Use(middleware.TimeoutMiddleware(cfg.Database.QueryTimeout))
     r.Use(middleware.LoggerMiddleware()) // Custom logger middleware
 
     logger.Info("Middleware configured successfully")
@@ -98,21 +140,50 @@ func main() {
             auth.POST("/logout", middleware.AuthMiddleware(cfg, userRepo), authHandler.Logout)
         }
 
+        // Signed download links carry their own credentials
+        api.GET("/files/:token", middleware.APIRateLimitMiddleware(), bookHandler.DownloadFile)
+
         protected := api.Group("")
         protected.Use(middleware.AuthMiddleware(cfg, userRepo))
         protected.Use(middleware.APIRateLimitMiddleware()) // Rate limit: 100 req/min
         {
+            protected.GET("/dashboard",
+                middleware.RoleMiddleware(models.RoleOwner),
+                statsHandler.GetDashboard)
+
             categories := protected.Group("/categories")
             {
                 categories.GET("", bookHandler.GetAllCategories)
+                categories.GET("/tree", bookHandler.GetCategoryTree)
                 categories.POST("",
                     middleware.RoleMiddleware(models.RoleOwner),
                     bookHandler.CreateCategory)
+                categories.PUT("/:id",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.UpdateCategory)
                 categories.DELETE("/:id",
                     middleware.RoleMiddleware(models.RoleOwner),
                     bookHandler.DeleteCategory)
             }
 
+            protected.GET("/tags", bookHandler.GetTags)
+
+            authors := protected.Group("/authors")
+            {
+                authors.GET("", bookHandler.GetAllAuthors)
+                authors.GET("/:id", bookHandler.GetAuthor)
+                authors.GET("/:id/books", bookHandler.GetBooksByAuthor)
+                authors.POST("",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.CreateAuthor)
+                authors.PUT("/:id",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.UpdateAuthor)
+                authors.DELETE("/:id",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.DeleteAuthor)
+            }
+
             books := protected.Group("/books")
             {
                 books.GET("", bookHandler.GetAllBooks)
@@ -122,6 +193,17 @@ func main() {
                     bookHandler.GetSavedBooks)
                 books.GET("/:id", bookHandler.GetBook)
                 books.GET("/:id/download", bookHandler.DownloadBook)
+                books.POST("/:id/download-link", bookHandler.CreateDownloadLink)
+                books.GET("/:id/read", bookHandler.ReadBook)
+                books.GET("/:id/cover", bookHandler.GetBookCover)
+                books.GET("/:id/progress", bookHandler.GetReadingProgress)
+                books.PUT("/:id/progress", bookHandler.UpdateReadingProgress)
+                books.GET("/:id/downloads",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.GetBookDownloads)
+                books.GET("/:id/statistics",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    statsHandler.GetBookStatistics)
                 books.POST("",
                     middleware.RoleMiddleware(models.RoleOwner),
                     bookHandler.CreateBook)
@@ -131,6 +213,21 @@ func main() {
                 books.DELETE("/:id", 
                     middleware.RoleMiddleware(models.RoleOwner), 
                     bookHandler.DeleteBook)
+                books.POST("/:id/restore",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.RestoreBook)
+                books.PUT("/:id/file",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.ReplaceBookFile)
+                books.GET("/:id/file/versions",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.GetBookFiles)
+                books.GET("/:id/file/versions/:version/download",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.DownloadBookFile)
+                books.POST("/:id/file/versions/:version/rollback",
+                    middleware.RoleMiddleware(models.RoleOwner),
+                    bookHandler.RollbackBookFile)
                 books.POST("/:id/save", 
                     middleware.RoleMiddleware(models.RoleMember), 
                     bookHandler.SaveBook)
@@ -138,8 +235,17 @@ func main() {
                     middleware.RoleMiddleware(models.RoleMember), 
                     bookHandler.UnsaveBook)
                 books.POST("/:id/like", bookHandler.LikeBook)
+                books.DELETE("/:id/like", bookHandler.RemoveLike)
                 books.GET("/:id/comments", bookHandler.GetComments)
                 books.POST("/:id/comments", bookHandler.AddComment)
+                books.PUT("/:id/comments/:commentId", bookHandler.UpdateComment)
+                books.DELETE("/:id/comments/:commentId", bookHandler.DeleteComment)
+            }
+
+            comments := protected.Group("/comments")
+            {
+                comments.POST("/:id/like", bookHandler.LikeComment)
+                comments.DELETE("/:id/like", bookHandler.RemoveCommentLike)
             }
         }
     }
@@ -153,8 +259,91 @@ func main() {
     }
 }
 
+func runMigrateCommand(migrator *migrate.Migrator, args []string) error {
+    ctx := context.Background()
+
+    command := "up"
+    if len(args) > 0 {
+        command = args[0]
+    }
+
+    switch command {
+    case "up":
+        applied, err := migrator.Up(ctx)
+        for _, m := range applied {
+            fmt.Printf("applied %03d_%s\n", m.Version, m.Name)
+        }
+        if err == nil && len(applied) == 0 {
+            fmt.Println("no pending migrations")
+        }
+        return err
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            n, err := strconv.Atoi(args[1])
+            if err != nil || n < 1 {
+                return fmt.Errorf("invalid number of steps %q", args[1])
+            }
+            steps = n
+        }
+        rolledBack, err := migrator.Down(ctx, steps)
+        for _, m := range rolledBack {
+            fmt.Printf("rolled back %03d_%s\n", m.Version, m.Name)
+        }
+        return err
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        for _, st := range statuses {
+            state := "pending"
+            if st.Applied {
+                state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+            }
+            fmt.Printf("%03d_%s\t%s\n", st.Version, st.Name, state)
+        }
+        return nil
+    default:
+        return fmt.Errorf("unknown migrate command %q (expected up, down [n] or status)", command)
+    }
+}
+
+// runUploadMaintenance periodically purges books whose retention period has
+// passed and reports stored files that no book refers to
+func runUploadMaintenance(bookService *service.BookService, cfg config.UploadConfig) {
+    if cfg.MaintenanceInterval <= 0 {
+        return
+    }
+
+    logger := utils.Logger
+    ticker := time.NewTicker(cfg.MaintenanceInterval)
+    defer ticker.Stop()
+
+    for {
+        ctx := context.Background()
+
+        purged, err := bookService.PurgeDeletedBooks(ctx, cfg.DeletedRetention)
+        if err != nil {
+            logger.WithError(err).Error("Failed to purge deleted books")
+        }
+        if purged > 0 {
+            logger.WithField("books", purged).Info("Purged deleted books")
+        }
+
+        orphans, err := bookService.FindOrphanedFiles(ctx)
+        if err != nil {
+            logger.WithError(err).Error("Failed to look for orphaned files")
+        }
+        for _, file := range orphans {
+            logger.WithField("file", file).Warn("Orphaned file in storage")
+        }
+
+        <-ticker.C
+    }
+}
 
-func createSuperAdmin(db *sql.DB) {
+func createSuperAdmin(ctx context.Context, db *sql.DB) {
 	logger := utils.Logger
 	email := os.Getenv("SUPER_ADMIN_EMAIL")
 	password := os.Getenv("SUPER_ADMIN_PASSWORD")
@@ -166,7 +355,7 @@ func createSuperAdmin(db *sql.DB) {
 
 	// Check if exists
 	userRepo := repository.NewUserRepository(db)
-	existing, err := userRepo.FindByEmail(email)
+	existing, err := userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		logger.WithError(err).Error("Error checking for existing admin")
 		return
@@ -192,7 +381,7 @@ func createSuperAdmin(db *sql.DB) {
 		Role:      models.RoleOwner,
 	}
 
-	if err := userRepo.Create(user); err != nil {
+	if err := userRepo.Create(ctx, user); err != nil {
 		logger.WithError(err).Error("Error creating super admin")
 		return
 	}
@@ -4,6 +4,8 @@ import (
     "fmt"
     "os"
     "strconv"
+    "time"
+
     "github.com/joho/godotenv"
 )
 
@@ -13,6 +15,7 @@ type Config struct {
     JWT      JWTConfig
     Server   ServerConfig
     Upload   UploadConfig
+    Comment  CommentConfig
 }
 
 type DatabaseConfig struct {
@@ -22,6 +25,10 @@ type DatabaseConfig struct {
     Password string
     DBName   string
     SSLMode  string
+    // AutoMigrate applies pending migrations when the server starts
+    AutoMigrate bool
+    // QueryTimeout bounds the database work of a single request; zero disables it
+    QueryTimeout time.Duration
 }
 
 type RedisConfig struct {
@@ -35,6 +42,8 @@ type JWTConfig struct {
     Secret               string
     ExpirationHours      int
     RefreshExpirationDays int
+    // DownloadLinkExpiry is how long signed download links stay valid
+    DownloadLinkExpiry time.Duration
 }
 
 type ServerConfig struct {
@@ -42,8 +51,40 @@ type ServerConfig struct {
 }
 
 type UploadConfig struct {
+    // Driver selects where book files are stored: "local" keeps them under
+    // Path, "s3" in the bucket described by S3
+    Driver      string
     Path        string
     MaxFileSize int64
+    // MaxCoverSize limits uploaded cover images, before they are scaled down
+    MaxCoverSize int64
+    // DeletedRetention is how long deleted books can be restored before their
+    // row and file are purged
+    DeletedRetention time.Duration
+    // MaintenanceInterval is how often deleted books are purged and orphaned
+    // files are looked for; zero disables the job
+    MaintenanceInterval time.Duration
+    S3                  S3Config
+}
+
+type S3Config struct {
+    // Endpoint of the object store, e.g. http://localhost:9000 for MinIO.
+    // Defaults to AWS S3 in Region.
+    Endpoint        string
+    Region          string
+    Bucket          string
+    AccessKeyID     string
+    SecretAccessKey string
+    // UsePathStyle addresses the bucket as part of the path instead of the
+    // host name, as MinIO and most self-hosted stores expect
+    UsePathStyle bool
+}
+
+type CommentConfig struct {
+    // MaxDepth is the deepest reply level allowed; top-level comments have depth 0
+    MaxDepth int
+    // EditWindow is how long after posting authors may still edit a comment
+    EditWindow time.Duration
 }
 
 func Load() (*Config, error) {
@@ -54,7 +95,16 @@ func Load() (*Config, error) {
     redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
     jwtExp, _ := strconv.Atoi(getEnv("JWT_EXPIRATION_HOURS", "24"))
     refreshExp, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRATION_DAYS", "7"))
+    downloadLinkExp, _ := strconv.Atoi(getEnv("DOWNLOAD_LINK_EXPIRATION_MINUTES", "5"))
     maxFileSize, _ := strconv.ParseInt(getEnv("MAX_FILE_SIZE", "10485760"), 10, 64)
+    maxCoverSize, _ := strconv.ParseInt(getEnv("MAX_COVER_SIZE", "5242880"), 10, 64)
+    commentMaxDepth, _ := strconv.Atoi(getEnv("COMMENT_MAX_DEPTH", "5"))
+    commentEditWindow, _ := strconv.Atoi(getEnv("COMMENT_EDIT_WINDOW_MINUTES", "15"))
+    autoMigrate, _ := strconv.ParseBool(getEnv("DB_AUTO_MIGRATE", "true"))
+    queryTimeout, _ := strconv.Atoi(getEnv("DB_QUERY_TIMEOUT_SECONDS", "10"))
+    deletedRetention, _ := strconv.Atoi(getEnv("DELETED_BOOK_RETENTION_DAYS", "30"))
+    maintenanceInterval, _ := strconv.Atoi(getEnv("UPLOAD_MAINTENANCE_INTERVAL_MINUTES", "60"))
+    s3PathStyle, _ := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "false"))
 
     return &Config{
         Database: DatabaseConfig{
@@ -64,6 +114,8 @@ func Load() (*Config, error) {
             Password: getEnv("DB_PASSWORD", "laziz2317"),
             DBName:   getEnv("DB_NAME", "bookgolang"),
             SSLMode:  getEnv("DB_SSLMODE", "disable"),
+            AutoMigrate: autoMigrate,
+            QueryTimeout: time.Duration(queryTimeout) * time.Second,
         },
         Redis: RedisConfig{
             Host:     getEnv("REDIS_HOST", "localhost"),
@@ -75,13 +127,30 @@ func Load() (*Config, error) {
             Secret:               getEnv("JWT_SECRET", "secret"),
             ExpirationHours:      jwtExp,
             RefreshExpirationDays: refreshExp,
+            DownloadLinkExpiry:    time.Duration(downloadLinkExp) * time.Minute,
         },
         Server: ServerConfig{
             Port: getEnv("SERVER_PORT", "8080"),
         },
         Upload: UploadConfig{
+            Driver:      getEnv("STORAGE_DRIVER", "local"),
             Path:        getEnv("UPLOAD_PATH", "./uploads"),
             MaxFileSize: maxFileSize,
+            MaxCoverSize: maxCoverSize,
+            DeletedRetention:    time.Duration(deletedRetention) * 24 * time.Hour,
+            MaintenanceInterval: time.Duration(maintenanceInterval) * time.Minute,
+            S3: S3Config{
+                Endpoint:        getEnv("S3_ENDPOINT", ""),
+                Region:          getEnv("S3_REGION", "us-east-1"),
+                Bucket:          getEnv("S3_BUCKET", ""),
+                AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+                SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+                UsePathStyle:    s3PathStyle,
+            },
+        },
+        Comment: CommentConfig{
+            MaxDepth:   commentMaxDepth,
+            EditWindow: time.Duration(commentEditWindow) * time.Minute,
         },
     }, nil
 }
@@ -0,0 +1,139 @@
+// Package cover turns uploaded cover images into the thumbnails shown in
+// book listings and draws placeholders for books without a cover. Only
+// pure Go image code is used, so no image libraries have to be installed.
+package cover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+// Size describes one of the thumbnail sizes covers are stored in
+type Size struct {
+	// Name is what the size query parameter selects
+	Name   string
+	Width  int
+	Height int
+}
+
+var (
+	Small  = &Size{Name: "small", Width: 120, Height: 180}
+	Medium = &Size{Name: "medium", Width: 240, Height: 360}
+	Large  = &Size{Name: "large", Width: 480, Height: 720}
+)
+
+// Sizes lists the thumbnail sizes generated for every cover
+var Sizes = []*Size{Small, Medium, Large}
+
+// SizeByName returns the thumbnail size called name
+func SizeByName(name string) (*Size, bool) {
+	for _, size := range Sizes {
+		if size.Name == name {
+			return size, true
+		}
+	}
+	return nil, false
+}
+
+// SizeNames returns the names of all sizes, e.g. for error messages
+func SizeNames() string {
+	names := make([]string, len(Sizes))
+	for i, size := range Sizes {
+		names[i] = size.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+const (
+	// ContentType is the type of the stored thumbnails
+	ContentType = "image/jpeg"
+	// PlaceholderContentType is the type of generated placeholders
+	PlaceholderContentType = "image/png"
+
+	jpegQuality = 85
+	// maxPixels bounds the decoded size of uploads so that a small file
+	// cannot expand into gigabytes of pixels
+	maxPixels = 40_000_000
+)
+
+// formats are the image formats accepted for uploads, as named by the
+// registered decoders
+var formats = map[string]bool{"jpeg": true, "png": true, "webp": true}
+
+// Decode checks that data is a JPEG, PNG or WebP image of a sensible size
+// and decodes it
+func Decode(data []byte) (image.Image, error) {
+	config, name, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !formats[name] {
+		return nil, errors.New("cover image must be a JPEG, PNG or WebP image")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("cover image dimensions %dx%d are not supported", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s cover image: %w", strings.ToUpper(name), err)
+	}
+	return img, nil
+}
+
+// Thumbnail scales src to fill the size, cropping the edges that do not
+// match its aspect ratio. Transparent areas become white since the
+// thumbnails are JPEGs.
+func Thumbnail(src image.Image, size *Size) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size.Width, size.Height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop(src.Bounds(), size), draw.Over, nil)
+	return dst
+}
+
+// crop returns the centered part of bounds with the aspect ratio of size
+func crop(bounds image.Rectangle, size *Size) image.Rectangle {
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*size.Height > h*size.Width {
+		// Too wide, cut the sides
+		cropped := h * size.Width / size.Height
+		x := bounds.Min.X + (w-cropped)/2
+		return image.Rect(x, bounds.Min.Y, x+cropped, bounds.Max.Y)
+	}
+	cropped := w * size.Height / size.Width
+	y := bounds.Min.Y + (h-cropped)/2
+	return image.Rect(bounds.Min.X, y, bounds.Max.X, y+cropped)
+}
+
+// Encode writes a thumbnail as a JPEG
+func Encode(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+// EncodePlaceholder writes a placeholder as a PNG, which keeps its flat
+// colors and the edges of the letters sharp
+func EncodePlaceholder(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// Key returns the storage key of the thumbnail in size of the cover stored
+// under base
+func Key(base string, size *Size) string {
+	return base + "/" + size.Name + ".jpg"
+}
+
+// Keys returns the storage keys of all thumbnails of the cover stored under base
+func Keys(base string) []string {
+	keys := make([]string, len(Sizes))
+	for i, size := range Sizes {
+		keys[i] = Key(base, size)
+	}
+	return keys
+}
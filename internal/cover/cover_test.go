@@ -0,0 +1,125 @@
+package cover
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	var pngData, jpegData, gifData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegData, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&gifData, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		content []byte
+		ok      bool
+	}{
+		{"png", pngData.Bytes(), true},
+		{"jpeg", jpegData.Bytes(), true},
+		{"gif", gifData.Bytes(), false},
+		{"truncated png", pngData.Bytes()[:len(pngData.Bytes())/2], false},
+		{"text", []byte("not an image"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := Decode(tc.content)
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("expected the image to be accepted: %v", err)
+				}
+				if decoded.Bounds().Dx() != 40 || decoded.Bounds().Dy() != 30 {
+					t.Errorf("unexpected bounds %v", decoded.Bounds())
+				}
+			} else if err == nil {
+				t.Error("expected the image to be rejected")
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// A wide image: red in the middle, blue at the sides that get cropped
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for x := 0; x < 300; x++ {
+		c := color.RGBA{B: 255, A: 255}
+		if x >= 100 && x < 200 {
+			c = color.RGBA{R: 255, A: 255}
+		}
+		for y := 0; y < 100; y++ {
+			src.Set(x, y, c)
+		}
+	}
+
+	for _, size := range Sizes {
+		thumb := Thumbnail(src, size)
+		if thumb.Bounds().Dx() != size.Width || thumb.Bounds().Dy() != size.Height {
+			t.Errorf("%s: expected %dx%d, got %v", size.Name, size.Width, size.Height, thumb.Bounds())
+		}
+		r, _, b, _ := thumb.At(size.Width/2, size.Height/2).RGBA()
+		if r < 0xf000 || b > 0x1000 {
+			t.Errorf("%s: expected the centered red part to be kept", size.Name)
+		}
+	}
+
+	// Transparent pixels are flattened on white
+	thumb := Thumbnail(image.NewNRGBA(image.Rect(0, 0, 20, 30)), Small)
+	if r, g, b, _ := thumb.At(5, 5).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+		t.Errorf("expected a white background, got %v", thumb.At(5, 5))
+	}
+}
+
+func TestPlaceholder(t *testing.T) {
+	a := Placeholder("The Go Programming Language", "book-a", Medium)
+	again := Placeholder("The Go Programming Language", "book-a", Medium)
+	b := Placeholder("The Go Programming Language", "book-b", Medium)
+
+	if a.Bounds().Dx() != Medium.Width || a.Bounds().Dy() != Medium.Height {
+		t.Fatalf("unexpected bounds %v", a.Bounds())
+	}
+	if a.At(0, 0) != again.At(0, 0) {
+		t.Error("expected the same book to get the same color")
+	}
+	if a.At(0, 0) == b.At(0, 0) {
+		t.Error("expected different books to get different colors")
+	}
+
+	var white int
+	for y := 0; y < Medium.Height; y++ {
+		for x := 0; x < Medium.Width; x++ {
+			if a.At(x, y) == (color.RGBA{255, 255, 255, 255}) {
+				white++
+			}
+		}
+	}
+	if white == 0 {
+		t.Error("expected the initials to be drawn")
+	}
+}
+
+func TestInitials(t *testing.T) {
+	for title, want := range map[string]string{
+		"the go programming language": "TG",
+		"Dune":                        "D",
+		"  ¿Qué pasa?  Ahora":         "PA",
+		"1984":                        "1",
+		"":                            "",
+		"— —":                         "",
+	} {
+		if got := Initials(title); got != want {
+			t.Errorf("Initials(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cover
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	boldFont     *opentype.Font
+	boldFontOnce sync.Once
+)
+
+// Placeholder draws the initials of title on a background color derived
+// from seed, usually the book ID, so a book keeps its color when renamed
+func Placeholder(title, seed string, size *Size) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size.Width, size.Height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background(seed)), image.Point{}, draw.Src)
+
+	text := Initials(title)
+	if text == "" {
+		return dst
+	}
+
+	face, err := opentype.NewFace(loadBoldFont(), &opentype.FaceOptions{
+		Size:    float64(size.Width) * 0.4,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return dst
+	}
+	defer face.Close()
+
+	drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(color.White), Face: face}
+	metrics := face.Metrics()
+	width := drawer.MeasureString(text)
+	drawer.Dot = fixed.Point26_6{
+		X: (fixed.I(size.Width) - width) / 2,
+		// Center the cap height, ascent minus descent approximates it
+		Y: (fixed.I(size.Height) + metrics.Ascent - metrics.Descent) / 2,
+	}
+	drawer.DrawString(text)
+	return dst
+}
+
+func loadBoldFont() *opentype.Font {
+	boldFontOnce.Do(func() {
+		// The font is embedded, parsing it cannot fail
+		boldFont, _ = opentype.Parse(gobold.TTF)
+	})
+	return boldFont
+}
+
+// Initials returns the upper-cased first letters of the first two words of
+// title. Words that do not start with a letter or digit are skipped.
+func Initials(title string) string {
+	var initials []rune
+	for _, word := range strings.Fields(title) {
+		first := []rune(word)[0]
+		if !unicode.IsLetter(first) && !unicode.IsDigit(first) {
+			continue
+		}
+		initials = append(initials, unicode.ToUpper(first))
+		if len(initials) == 2 {
+			break
+		}
+	}
+	return string(initials)
+}
+
+// background picks a muted color from the hash of seed. Saturation and
+// lightness are fixed so white letters stay readable on every hue.
+func background(seed string) color.RGBA {
+	hash := fnv.New32a()
+	hash.Write([]byte(seed))
+	hue := float64(hash.Sum32() % 360)
+	return hsl(hue, 0.45, 0.42)
+}
+
+// hsl converts a hue in degrees and saturation and lightness in [0, 1] to RGB
+func hsl(h, s, l float64) color.RGBA {
+	c := (1 - abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - abs(mod2(hp)-1))
+
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = c, x
+	case hp < 2:
+		r, g = x, c
+	case hp < 3:
+		g, b = c, x
+	case hp < 4:
+		g, b = x, c
+	case hp < 5:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+
+	m := l - c/2
+	return color.RGBA{
+		R: uint8((r + m) * 255),
+		G: uint8((g + m) * 255),
+		B: uint8((b + m) * 255),
+		A: 255,
+	}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// mod2 returns v modulo 2 for non-negative v
+func mod2(v float64) float64 {
+	return v - 2*float64(int(v/2))
+}
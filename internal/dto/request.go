@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // Auth Requests
 type RegisterRequest struct {
 	Email     string `json:"email" binding:"required,email"`
@@ -18,27 +20,72 @@ type RefreshTokenRequest struct {
 }
 
 // Book Requests
+
+// CreateBookRequest holds the form fields of a book upload. Fields left
+// empty are filled in from the metadata of PDF files; the title is required
+// only when the file has none.
 type CreateBookRequest struct {
-	Title       string `form:"title" binding:"required"`
-	Description string `form:"description" binding:"required"`
-	CategoryID  string `form:"category_id" binding:"required"`
+	Title       string   `form:"title"`
+	Description string   `form:"description"`
+	CategoryID  string   `form:"category_id" binding:"required"`
+	Author      string   `form:"author" binding:"max=255"`
+	Keywords    string   `form:"keywords"`
+	AuthorIDs   []string `form:"author_ids"`
+	Tags        []string `form:"tags"`
 }
 
+// UpdateBookRequest is sent as JSON, or as a multipart form when it comes
+// with a new cover image. Omitting author_ids or tags keeps the current
+// authors or tags.
 type UpdateBookRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
-	CategoryID  string `json:"category_id" binding:"required"`
+	Title       string    `json:"title" form:"title" binding:"required"`
+	Description string    `json:"description" form:"description" binding:"required"`
+	CategoryID  string    `json:"category_id" form:"category_id" binding:"required"`
+	AuthorIDs   *[]string `json:"author_ids" form:"author_ids"`
+	Tags        *[]string `json:"tags" form:"tags"`
+}
+
+// Author Requests
+type CreateAuthorRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Bio  string `json:"bio"`
+}
+
+type UpdateAuthorRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Bio  string `json:"bio"`
 }
 
 // Category Requests
 type CreateCategoryRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	ParentID    *string `json:"parent_id,omitempty"`
 }
 
+// UpdateCategoryRequest replaces the name and description of the category.
+// Omitting parent_id keeps the category where it is.
 type UpdateCategoryRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	// Omit to keep the current parent; null or an empty string moves the category to the top level
+	ParentID OptionalString `json:"parent_id" swaggertype:"string"`
+}
+
+// OptionalString tells a JSON field that was left out from one set to null.
+// Set is false when the field is absent; Value is nil when it is null.
+type OptionalString struct {
+	Set   bool
+	Value *string
+}
+
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
 }
 
 // Comment Requests
@@ -47,6 +94,10 @@ type CreateCommentRequest struct {
 	ParentID *string `json:"parent_id,omitempty"`
 }
 
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required,min=1,max=1000"`
+}
+
 // Like Requests
 type LikeRequest struct {
 	IsLike bool `json:"is_like"`
@@ -56,17 +107,39 @@ type RemoveLikeRequest struct {
 	BookID string `json:"book_id" binding:"required"`
 }
 
+// Reading Progress Request
+type UpdateReadingProgressRequest struct {
+	Page int `json:"page" binding:"required,min=1"`
+}
+
 // Pagination Request
 type PaginationRequest struct {
 	Page     int    `form:"page" binding:"omitempty,min=1"`
 	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
-	SortBy   string `form:"sort_by" binding:"omitempty,oneof=created_at updated_at save_count title"`
+	SortBy   string `form:"sort_by" binding:"omitempty,oneof=created_at updated_at save_count title like_count download_count rating page_count"`
 	Order    string `form:"order" binding:"omitempty,oneof=asc desc"`
 }
 
-// Filter Request
+// Filter Request. Tags is a comma separated list; Match decides whether books
+// need all of the tags (the default) or any of them.
 type BookFilterRequest struct {
 	CategoryID string `form:"category_id"`
 	Search     string `form:"search"`
+	MinPages   int    `form:"min_pages" binding:"omitempty,min=1"`
+	MaxPages   int    `form:"max_pages" binding:"omitempty,min=1,gtefield=MinPages"`
+	Tags       string `form:"tags"`
+	Match      string `form:"match" binding:"omitempty,oneof=all any"`
 	PaginationRequest
-}
\ No newline at end of file
+}
+
+// Tag Request
+type TagSearchRequest struct {
+	Prefix string `form:"prefix"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// Statistics Request
+type StatisticsRequest struct {
+	Interval string `form:"interval" binding:"omitempty,oneof=day week"`
+	Days     int    `form:"days" binding:"omitempty,min=1,max=365"`
+}
@@ -43,18 +43,32 @@ type UserResponse struct {
 
 // Book Responses
 type BookResponse struct {
-	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	PDFFile      string    `json:"pdf_file"`
-	CategoryID   string    `json:"category_id"`
-	CategoryName string    `json:"category_name"`
-	OwnerID      string    `json:"owner_id"`
-	LikeCount    int       `json:"like_count"`
-	DislikeCount int       `json:"dislike_count"`
-	SaveCount    int       `json:"save_count"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                string          `json:"id"`
+	Title             string          `json:"title"`
+	Description       string          `json:"description"`
+	PDFFile           string          `json:"pdf_file"`
+	Format            string          `json:"format"`
+	CoverURL          string          `json:"cover_url"`
+	HasCover          bool            `json:"has_cover"`
+	CategoryID        string          `json:"category_id"`
+	CategoryName      string          `json:"category_name"`
+	OwnerID           string          `json:"owner_id"`
+	Authors           []AuthorSummary `json:"authors"`
+	Tags              []string        `json:"tags"`
+	Author            string          `json:"author"`
+	Subject           string          `json:"subject"`
+	Keywords          string          `json:"keywords"`
+	PageCount         int             `json:"page_count"`
+	DocumentCreatedAt *time.Time      `json:"document_created_at,omitempty"`
+	LikeCount         int             `json:"like_count"`
+	DislikeCount      int             `json:"dislike_count"`
+	SaveCount         int             `json:"save_count"`
+	DownloadCount     int             `json:"download_count"`
+	UserLiked         bool            `json:"user_liked"`
+	UserDisliked      bool            `json:"user_disliked"`
+	UserSaved         bool            `json:"user_saved"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
 }
 
 type BookListResponse struct {
@@ -67,12 +81,44 @@ type BookDetailResponse struct {
 	Owner    UserResponse      `json:"owner"`
 }
 
+// Author Responses
+type AuthorResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	BookCount int       `json:"book_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AuthorListResponse struct {
+	Authors    []AuthorResponse   `json:"authors"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// AuthorSummary names one of the authors of a book
+type AuthorSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Tag Responses
+type TagResponse struct {
+	Name      string `json:"name"`
+	BookCount int    `json:"book_count"`
+}
+
+type TagListResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
+
 // Category Responses
 type CategoryResponse struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	BookCount   int       `json:"book_count,omitempty"`
+	ParentID    *string   `json:"parent_id,omitempty"`
+	BookCount   int       `json:"book_count"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -81,22 +127,43 @@ type CategoryListResponse struct {
 	Categories []CategoryResponse `json:"categories"`
 }
 
+// CategoryTreeNode is a category with its nested subcategories.
+// TotalBookCount includes the books of all descendants.
+type CategoryTreeNode struct {
+	CategoryResponse
+	TotalBookCount int                 `json:"total_book_count"`
+	Children       []*CategoryTreeNode `json:"children"`
+}
+
+type CategoryTreeResponse struct {
+	Categories []*CategoryTreeNode `json:"categories"`
+}
+
 // Comment Responses
 type CommentResponse struct {
-	ID            string    `json:"id"`
-	BookID        string    `json:"book_id"`
-	UserID        string    `json:"user_id"`
-	UserFirstName string    `json:"user_first_name"`
-	UserLastName  string    `json:"user_last_name"`
-	Content       string    `json:"content"`
-	ParentID      *string   `json:"parent_id,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string             `json:"id"`
+	BookID        string             `json:"book_id"`
+	UserID        string             `json:"user_id"`
+	UserFirstName string             `json:"user_first_name"`
+	UserLastName  string             `json:"user_last_name"`
+	Content       string             `json:"content"`
+	ParentID      *string            `json:"parent_id,omitempty"`
+	Depth         int                `json:"depth"`
+	LikeCount     int                `json:"like_count"`
+	DislikeCount  int                `json:"dislike_count"`
+	IsDeleted     bool               `json:"is_deleted"`
+	ReplyCount    int                `json:"reply_count"`
+	Replies       []*CommentResponse `json:"replies,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
 }
 
+// CommentListResponse is paginated on top-level threads. Total is the number
+// of comments on the book including replies.
 type CommentListResponse struct {
-	Comments []CommentResponse `json:"comments"`
-	Total    int               `json:"total"`
+	Comments   []*CommentResponse `json:"comments"`
+	Total      int                `json:"total"`
+	Pagination PaginationResponse `json:"pagination"`
 }
 
 // Like Responses
@@ -108,6 +175,14 @@ type LikeResponse struct {
 	DislikeCount int    `json:"dislike_count"`
 }
 
+type CommentLikeResponse struct {
+	CommentID    string `json:"comment_id"`
+	UserLiked    bool   `json:"user_liked"`
+	UserDisliked bool   `json:"user_disliked"`
+	LikeCount    int    `json:"like_count"`
+	DislikeCount int    `json:"dislike_count"`
+}
+
 // Saved Book Responses
 type SavedBookResponse struct {
 	ID        string       `json:"id"`
@@ -121,6 +196,53 @@ type SavedBookListResponse struct {
 	Pagination PaginationResponse  `json:"pagination"`
 }
 
+// Download Responses
+type DownloadResponse struct {
+	ID            string    `json:"id"`
+	BookID        string    `json:"book_id"`
+	UserID        string    `json:"user_id"`
+	UserEmail     string    `json:"user_email"`
+	UserFirstName string    `json:"user_first_name"`
+	UserLastName  string    `json:"user_last_name"`
+	IPAddress     string    `json:"ip_address,omitempty"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+	DownloadedAt  time.Time `json:"downloaded_at"`
+}
+
+type DownloadListResponse struct {
+	Downloads  []DownloadResponse `json:"downloads"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// Book File Responses
+type BookFileResponse struct {
+	ID           string    `json:"id"`
+	Version      int       `json:"version"`
+	Format       string    `json:"format"`
+	PageCount    int       `json:"page_count"`
+	UploadedBy   string    `json:"uploaded_by,omitempty"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	Current      bool      `json:"current"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type BookFileListResponse struct {
+	Files []BookFileResponse `json:"files"`
+}
+
+// Download Link Response
+type DownloadLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Reading Progress Response
+type ReadingProgressResponse struct {
+	BookID    string    `json:"book_id"`
+	Page      int       `json:"page"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // Pagination Response
 type PaginationResponse struct {
 	CurrentPage int   `json:"current_page"`
@@ -143,10 +265,23 @@ type DashboardResponse struct {
 
 // Statistics Response
 type BookStatisticsResponse struct {
-	BookID       string `json:"book_id"`
-	Views        int    `json:"views"`
-	Saves        int    `json:"saves"`
-	Likes        int    `json:"likes"`
-	Dislikes     int    `json:"dislikes"`
-	Comments     int    `json:"comments"`
+	BookID    string                   `json:"book_id"`
+	Views     int                      `json:"views"`
+	Saves     int                      `json:"saves"`
+	Likes     int                      `json:"likes"`
+	Dislikes  int                      `json:"dislikes"`
+	Comments  int                      `json:"comments"`
+	Downloads int                      `json:"downloads"`
+	Interval  string                   `json:"interval"`
+	Timeline  []ActivityBucketResponse `json:"timeline"`
+}
+
+type ActivityBucketResponse struct {
+	Period    time.Time `json:"period"`
+	Views     int       `json:"views"`
+	Downloads int       `json:"downloads"`
+	Saves     int       `json:"saves"`
+	Likes     int       `json:"likes"`
+	Dislikes  int       `json:"dislikes"`
+	Comments  int       `json:"comments"`
 }
\ No newline at end of file
@@ -0,0 +1,235 @@
+// Package format recognizes the e-book formats the library accepts. Files
+// are identified by their content, magic bytes and structure, not by the
+// name or the content type the client claims.
+package format
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format describes a supported e-book format
+type Format struct {
+	// Name is what books store to record their format
+	Name        string
+	Extension   string
+	ContentType string
+	// check returns an error describing why the content is not in the format
+	check func(r io.ReaderAt, size int64) error
+}
+
+var (
+	PDF = &Format{
+		Name:        "pdf",
+		Extension:   ".pdf",
+		ContentType: "application/pdf",
+		check:       checkPDF,
+	}
+	EPUB = &Format{
+		Name:        "epub",
+		Extension:   ".epub",
+		ContentType: "application/epub+zip",
+		check:       checkEPUB,
+	}
+	DJVU = &Format{
+		Name:        "djvu",
+		Extension:   ".djvu",
+		ContentType: "image/vnd.djvu",
+		check:       checkDJVU,
+	}
+	TXT = &Format{
+		Name:        "txt",
+		Extension:   ".txt",
+		ContentType: "text/plain; charset=utf-8",
+		check:       checkTXT,
+	}
+)
+
+// registry lists the supported formats
+var registry = []*Format{PDF, EPUB, DJVU, TXT}
+
+// aliases maps further file extensions to their format
+var aliases = map[string]*Format{
+	".djv":  DJVU,
+	".text": TXT,
+}
+
+// ByName returns the format books record as name
+func ByName(name string) (*Format, bool) {
+	for _, f := range registry {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// ByExtension returns the format of a file name extension such as ".epub"
+func ByExtension(ext string) (*Format, bool) {
+	ext = strings.ToLower(ext)
+	if f, ok := aliases[ext]; ok {
+		return f, true
+	}
+	for _, f := range registry {
+		if f.Extension == ext {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Validate returns the format of the file named filename, making sure the
+// content really is in the format its extension announces
+func Validate(filename string, r io.ReaderAt, size int64) (*Format, error) {
+	f, ok := ByExtension(filepath.Ext(filename))
+	if !ok {
+		return nil, fmt.Errorf("unsupported file type, allowed are %s", extensions())
+	}
+	if err := f.check(r, size); err != nil {
+		return nil, fmt.Errorf("invalid %s file: %w", strings.ToUpper(f.Name), err)
+	}
+	return f, nil
+}
+
+func extensions() string {
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Extension
+	}
+	return strings.Join(names, ", ")
+}
+
+// pdfSearchWindow is how far from the start and the end of a PDF readers
+// look for the header and the end-of-file marker
+const pdfSearchWindow = 1024
+
+func checkPDF(r io.ReaderAt, size int64) error {
+	head := readAt(r, 0, min(size, pdfSearchWindow))
+	if !bytes.Contains(head, []byte("%PDF-")) {
+		return errors.New("missing %PDF- header")
+	}
+	tail := readAt(r, max(size-pdfSearchWindow, 0), min(size, pdfSearchWindow))
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return errors.New("missing %%EOF marker, the file may be truncated")
+	}
+	return nil
+}
+
+const epubMimetype = "application/epub+zip"
+
+// checkEPUB follows the Open Container Format: a zip archive whose first
+// entry is a mimetype file and whose META-INF/container.xml points at the
+// package document
+func checkEPUB(r io.ReaderAt, size int64) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return errors.New("not a zip archive")
+	}
+	if len(archive.File) == 0 || archive.File[0].Name != "mimetype" {
+		return errors.New("the first entry must be the mimetype file")
+	}
+	mimetype, err := readEntry(archive.File[0], 64)
+	if err != nil || strings.TrimSpace(string(mimetype)) != epubMimetype {
+		return fmt.Errorf("the mimetype file must contain %s", epubMimetype)
+	}
+
+	var container *zip.File
+	entries := make(map[string]bool, len(archive.File))
+	for _, file := range archive.File {
+		entries[file.Name] = true
+		if file.Name == "META-INF/container.xml" {
+			container = file
+		}
+	}
+	if container == nil {
+		return errors.New("missing META-INF/container.xml")
+	}
+
+	data, err := readEntry(container, 1<<20)
+	if err != nil {
+		return errors.New("unreadable META-INF/container.xml")
+	}
+	var doc struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return errors.New("malformed META-INF/container.xml")
+	}
+	if len(doc.Rootfiles) == 0 {
+		return errors.New("META-INF/container.xml lists no package document")
+	}
+	for _, rootfile := range doc.Rootfiles {
+		if !entries[rootfile.FullPath] {
+			return fmt.Errorf("missing package document %s", rootfile.FullPath)
+		}
+	}
+	return nil
+}
+
+// checkDJVU accepts single page (DJVU) and bundled multi-page (DJVM)
+// documents, an IFF85 FORM chunk behind the AT&T magic
+func checkDJVU(r io.ReaderAt, size int64) error {
+	header := readAt(r, 0, 16)
+	if len(header) < 16 || string(header[:8]) != "AT&TFORM" {
+		return errors.New("missing AT&TFORM header")
+	}
+	switch string(header[12:16]) {
+	case "DJVU", "DJVM":
+	case "DJVI", "THUM":
+		return errors.New("shared and thumbnail files are not documents")
+	default:
+		return errors.New("unknown DjVu form type")
+	}
+	if length := int64(binary.BigEndian.Uint32(header[8:12])); 12+length > size {
+		return errors.New("the file is truncated")
+	}
+	return nil
+}
+
+// checkTXT accepts UTF-8 text without control characters other than
+// whitespace
+func checkTXT(r io.ReaderAt, size int64) error {
+	if size == 0 {
+		return errors.New("the file is empty")
+	}
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return errors.New("the text is not UTF-8 encoded")
+	}
+	for _, c := range data {
+		if (c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f') || c == 0x7f {
+			return errors.New("the file contains binary data")
+		}
+	}
+	return nil
+}
+
+// readAt returns up to n bytes from offset, fewer when the content is shorter
+func readAt(r io.ReaderAt, offset, n int64) []byte {
+	buf := make([]byte, n)
+	read, _ := r.ReadAt(buf, offset)
+	return buf[:read]
+}
+
+func readEntry(file *zip.File, limit int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
@@ -0,0 +1,118 @@
+package format
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	epub := buildZip(t, []zipEntry{
+		{"mimetype", "application/epub+zip", zip.Store},
+		{"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`, zip.Deflate},
+		{"OEBPS/content.opf", "<package/>", zip.Deflate},
+	})
+	docx := buildZip(t, []zipEntry{
+		{"[Content_Types].xml", "<Types/>", zip.Deflate},
+		{"word/document.xml", "<document/>", zip.Deflate},
+	})
+	noContainer := buildZip(t, []zipEntry{{"mimetype", "application/epub+zip", zip.Store}})
+	missingPackage := buildZip(t, []zipEntry{
+		{"mimetype", "application/epub+zip", zip.Store},
+		{"META-INF/container.xml", `<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`, zip.Deflate},
+	})
+
+	for _, tc := range []struct {
+		name     string
+		filename string
+		content  []byte
+		want     *Format
+	}{
+		{"pdf", "book.pdf", pdf, PDF},
+		{"upper case extension", "BOOK.PDF", pdf, PDF},
+		{"truncated pdf", "book.pdf", pdf[:40], nil},
+		{"epub", "book.epub", epub, EPUB},
+		{"docx renamed to epub", "book.epub", docx, nil},
+		{"epub without container", "book.epub", noContainer, nil},
+		{"epub without package document", "book.epub", missingPackage, nil},
+		{"docx", "book.docx", docx, nil},
+		{"pdf renamed to epub", "book.epub", pdf, nil},
+		{"djvu", "book.djvu", djvu("DJVM", 32), DJVU},
+		{"djv extension", "book.djv", djvu("DJVU", 32), DJVU},
+		{"truncated djvu", "book.djvu", djvu("DJVM", 32)[:24], nil},
+		{"djvu thumbnails", "book.djvu", djvu("THUM", 32), nil},
+		{"text", "book.txt", []byte("\xef\xbb\xbfCall me Ishmael.\r\n\tSome years ago…\n"), TXT},
+		{"latin-1 text", "book.txt", []byte("caf\xe9"), nil},
+		{"binary text", "book.txt", []byte("MZ\x90\x00\x03"), nil},
+		{"empty text", "book.txt", nil, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Validate(tc.filename, bytes.NewReader(tc.content), int64(len(tc.content)))
+			if tc.want == nil {
+				if err == nil {
+					t.Fatalf("expected an error, got format %s", got.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want.Name, got.Name)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if f, ok := ByName("epub"); !ok || f != EPUB {
+		t.Errorf("expected epub to be registered")
+	}
+	if _, ok := ByName("docx"); ok {
+		t.Errorf("expected docx to be unsupported")
+	}
+	if f, ok := ByExtension(".TEXT"); !ok || f != TXT {
+		t.Errorf("expected .TEXT to be plain text")
+	}
+}
+
+type zipEntry struct {
+	name    string
+	content string
+	method  uint16
+}
+
+func buildZip(t *testing.T, entries []zipEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: entry.name, Method: entry.method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// djvu builds an IFF85 FORM of the given type with length bytes of payload
+func djvu(formType string, length int) []byte {
+	data := []byte("AT&TFORM")
+	data = binary.BigEndian.AppendUint32(data, uint32(4+length))
+	data = append(data, formType...)
+	return append(data, make([]byte, length)...)
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
     "library-project/internal/dto"
+    "library-project/internal/middleware"
     "library-project/internal/service"
+    "library-project/internal/utils"
     "net/http"
 
     "github.com/gin-gonic/gin"
@@ -25,6 +27,7 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // @Param request body dto.RegisterRequest true "Register Request"
 // @Success 201 {object} dto.AuthResponse
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} dto.ErrorResponse
 // @Failure 500 {object} map[string]string
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -34,9 +37,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
         return
     }
 
-    response, err := h.authService.Register(&req)
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.authService.Register(ctx, &req)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
@@ -52,7 +58,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Param request body dto.LoginRequest true "Login Request"
 // @Success 200 {object} dto.AuthResponse
 // @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 401 {object} dto.ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
     var req dto.LoginRequest
@@ -61,9 +67,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
         return
     }
 
-    response, err := h.authService.Login(&req)
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.authService.Login(ctx, &req)
     if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
@@ -79,7 +88,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Param request body dto.RefreshTokenRequest true "Refresh Token Request"
 // @Success 200 {object} dto.AuthResponse
 // @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 401 {object} dto.ErrorResponse
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
     var req dto.RefreshTokenRequest
@@ -88,9 +97,12 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
         return
     }
 
-    response, err := h.authService.RefreshToken(&req)
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.authService.RefreshToken(ctx, &req)
     if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
@@ -110,8 +122,11 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 func (h *AuthHandler) Logout(c *gin.Context) {
     userID := c.GetString("user_id")
 
-    if err := h.authService.Logout(userID); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.authService.Logout(ctx, userID); err != nil {
+        utils.HandleError(c, err)
         return
     }
 
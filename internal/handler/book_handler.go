@@ -1,43 +1,65 @@
 package handler
 
 import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
     "fmt"
+    "io"
     "library-project/config"
+    "library-project/internal/cover"
     "library-project/internal/dto"
+    "library-project/internal/format"
+    "library-project/internal/middleware"
+    "library-project/internal/models"
+    "library-project/internal/pdfmeta"
     "library-project/internal/service"
+    "library-project/internal/storage"
     "library-project/internal/utils"
+    "mime"
     "net/http"
-    "os"
-    "path/filepath"
     "strconv"
+    "strings"
 
     "github.com/gin-gonic/gin"
     "github.com/google/uuid"
 )
 
+// downloadLinkPath is where the route serving signed download links is mounted
+const downloadLinkPath = "/api/v1/files/"
+
 type BookHandler struct {
     bookService *service.BookService
+    files       storage.Storage
     cfg         *config.Config
 }
 
-func NewBookHandler(bookService *service.BookService, cfg *config.Config) *BookHandler {
+func NewBookHandler(bookService *service.BookService, files storage.Storage, cfg *config.Config) *BookHandler {
     return &BookHandler{
         bookService: bookService,
+        files:       files,
         cfg:         cfg,
     }
 }
 
 // CreateBook godoc
 // @Summary Create a new book (Owner only)
-// @Description Upload a book with PDF file (Owner only)
+// @Description Upload a book file in PDF, EPUB, DjVu or plain text format (Owner only). The format is checked against the content, not only the extension. For PDFs, empty fields are filled in from the document metadata (title, author, subject as description, keywords), and the page count and creation date are stored. An optional JPEG, PNG or WebP cover image is scaled into thumbnails served by GET /books/{id}/cover.
 // @Tags books
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param title formData string true "Book Title"
-// @Param description formData string true "Book Description"
+// @Param title formData string false "Book Title, required unless the PDF metadata has one"
+// @Param description formData string false "Book Description"
+// @Param author formData string false "Author, linked as the book's author when author_ids is empty. An author of the same name is reused, otherwise one is created"
+// @Param keywords formData string false "Keywords"
 // @Param category_id formData string true "Category ID"
-// @Param pdf_file formData file true "PDF File"
+// @Param author_ids formData []string false "IDs of the book's authors in order of appearance" collectionFormat(multi)
+// @Param tags formData []string false "Tags, each value may hold several comma separated tags" collectionFormat(multi)
+// @Param pdf_file formData file true "Book file (.pdf, .epub, .djvu or .txt)"
+// @Param cover_image formData file false "Cover image (JPEG, PNG or WebP)"
 // @Success 201 {object} dto.BookResponse 
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -51,92 +73,345 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
         return
     }
 
-    file, err := c.FormFile("pdf_file")
+    upload, meta, ok := h.receiveUpload(c)
+    if !ok {
+        return
+    }
+
+    coverImage, ok := h.receiveCover(c)
+    if !ok {
+        h.discardUpload(c, upload.PDFFile)
+        return
+    }
+
+    userID := c.GetString("user_id")
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    book, err := h.bookService.CreateBook(ctx, &req, upload, meta, coverImage, userID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "PDF file is required"})
+        h.discardUpload(c, upload.PDFFile)
+        h.discardCover(c, coverImage)
+        utils.HandleError(c, err)
         return
     }
 
-    // Validate PDF file (MIME type, size, extension)
-    if err := utils.ValidatePDFFile(file, h.cfg.Upload.MaxFileSize); err != nil {
+    c.JSON(http.StatusCreated, book)
+}
+
+// receiveUpload validates the book file in the pdf_file form field and stores
+// it under a new name. The returned version has its file, hash, format and
+// page count set; meta is the metadata of PDF files and nil for other
+// formats or when it cannot be read. The error response has been written
+// when ok is false.
+func (h *BookHandler) receiveUpload(c *gin.Context) (upload *models.BookFile, meta *pdfmeta.Metadata, ok bool) {
+    file, err := c.FormFile("pdf_file")
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Book file is required"})
+        return nil, nil, false
+    }
+
+    // Validate the file (size, extension and that the content matches it)
+    bookFormat, err := utils.ValidateBookFile(file, h.cfg.Upload.MaxFileSize)
+    if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
+        return nil, nil, false
+    }
+
+    filename := uuid.New().String() + bookFormat.Extension
+
+    src, err := file.Open()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+        return nil, nil, false
     }
+    defer src.Close()
 
-    filename := fmt.Sprintf("%s%s", uuid.New().String(), filepath.Ext(file.Filename))
-    filepath := filepath.Join(h.cfg.Upload.Path, filename)
+    // Metadata is a convenience; a PDF it cannot be read from is still a book
+    upload = &models.BookFile{Format: bookFormat.Name}
+    if bookFormat == format.PDF {
+        if meta, err = pdfmeta.Extract(src, file.Size); err == nil {
+            upload.PageCount = meta.PageCount
+        }
+    }
 
-    if err := c.SaveUploadedFile(file, filepath); err != nil {
+    // Hash the file while storing it; the hash is the ETag of its downloads
+    digest := sha256.New()
+    if err := h.files.Put(c.Request.Context(), filename, io.TeeReader(src, digest), file.Size, bookFormat.ContentType); err != nil {
+        utils.LogError(err, "Failed to store uploaded file", map[string]interface{}{
+            "file": filename,
+        })
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
-        return
+        return nil, nil, false
     }
 
-    userID := c.GetString("user_id")
-    book, err := h.bookService.CreateBook(&req, filename, userID)
+    upload.PDFFile = filename
+    upload.FileHash = hex.EncodeToString(digest.Sum(nil))
+    return upload, meta, true
+}
+
+// discardUpload removes a stored upload that no book ended up referencing,
+// even when the request itself was canceled
+func (h *BookHandler) discardUpload(c *gin.Context, filename string) {
+    if err := h.files.Delete(context.WithoutCancel(c.Request.Context()), filename); err != nil {
+        utils.LogError(err, "Failed to remove uploaded file", map[string]interface{}{
+            "file": filename,
+        })
+    }
+}
+
+// receiveCover validates the optional image in the cover_image form field and
+// stores its thumbnails under a new key prefix, which is returned. The
+// prefix is empty when no cover was sent. The error response has been
+// written when ok is false.
+func (h *BookHandler) receiveCover(c *gin.Context) (coverImage string, ok bool) {
+    file, err := c.FormFile("cover_image")
+    if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+        return "", true
+    }
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cover image"})
+        return "", false
+    }
+    if file.Size > h.cfg.Upload.MaxCoverSize {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "cover image size exceeds maximum limit"})
+        return "", false
+    }
+
+    src, err := file.Open()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read cover image"})
+        return "", false
+    }
+    defer src.Close()
+
+    data, err := io.ReadAll(io.LimitReader(src, h.cfg.Upload.MaxCoverSize))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read cover image"})
+        return "", false
+    }
+
+    img, err := cover.Decode(data)
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return "", false
+    }
+
+    coverImage = "covers/" + uuid.New().String()
+    for _, size := range cover.Sizes {
+        var thumbnail bytes.Buffer
+        err := cover.Encode(&thumbnail, cover.Thumbnail(img, size))
+        if err == nil {
+            key := cover.Key(coverImage, size)
+            err = h.files.Put(c.Request.Context(), key, &thumbnail, int64(thumbnail.Len()), cover.ContentType)
+        }
+        if err != nil {
+            utils.LogError(err, "Failed to store cover thumbnail", map[string]interface{}{
+                "cover": coverImage,
+                "size":  size.Name,
+            })
+            h.discardCover(c, coverImage)
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save cover image"})
+            return "", false
+        }
+    }
+
+    return coverImage, true
+}
+
+// discardCover removes the stored thumbnails of a cover that no book ended up
+// referencing, even when the request itself was canceled
+func (h *BookHandler) discardCover(c *gin.Context, coverImage string) {
+    if coverImage == "" {
         return
     }
+    for _, key := range cover.Keys(coverImage) {
+        h.discardUpload(c, key)
+    }
+}
 
-    c.JSON(http.StatusCreated, book)
+// ReplaceBookFile godoc
+// @Summary Replace the file of a book (Owner only)
+// @Description Upload a new file for an existing book, e.g. to fix a bad scan. It may be in another supported format than before. Likes, saves and comments are kept. The previous file stays available as an earlier version.
+// @Tags books
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param pdf_file formData file true "Book file (.pdf, .epub, .djvu or .txt)"
+// @Success 200 {object} dto.BookFileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/file [put]
+func (h *BookHandler) ReplaceBookFile(c *gin.Context) {
+    upload, _, ok := h.receiveUpload(c)
+    if !ok {
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    version, err := h.bookService.ReplaceBookFile(ctx, c.Param("id"), c.GetString("user_id"), upload)
+    if err != nil {
+        h.discardUpload(c, upload.PDFFile)
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.MapBookFileToResponse(version, true))
+}
+
+// GetBookFiles godoc
+// @Summary List the file versions of a book (Owner only)
+// @Description List every file a book has had, newest first. The newest version is the current file.
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} dto.BookFileListResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/file/versions [get]
+func (h *BookHandler) GetBookFiles(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    files, err := h.bookService.GetBookFiles(ctx, c.Param("id"), c.GetString("user_id"))
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, dto.BookFileListResponse{Files: utils.MapBookFilesToResponse(files)})
+}
+
+// DownloadBookFile godoc
+// @Summary Download a file version of a book (Owner only)
+// @Description Download the file of any version of a book. Downloads of versions are not counted in the download statistics.
+// @Tags books
+// @Produce application/pdf,application/epub+zip,image/vnd.djvu,text/plain
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param version path int true "Version number"
+// @Param Range header string false "Byte ranges, e.g. bytes=0-1023"
+// @Success 200 {file} binary "Book file"
+// @Success 206 {file} binary "Requested byte ranges"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/file/versions/{version}/download [get]
+func (h *BookHandler) DownloadBookFile(c *gin.Context) {
+    version, ok := versionParam(c)
+    if !ok {
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    book, file, err := h.bookService.GetBookFile(ctx, c.Param("id"), version, c.GetString("user_id"))
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    name := fmt.Sprintf("%s (v%d)", book.Title, file.Version)
+    h.serveFile(c, file.PDFFile, file.FileHash, formatOf(file.Format), name, "attachment", nil)
+}
+
+// RollbackBookFile godoc
+// @Summary Roll back the file of a book (Owner only)
+// @Description Make the file of an earlier version the current file again. It is added as a new version, so the history is kept.
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param version path int true "Version to restore"
+// @Success 200 {object} dto.BookFileResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/file/versions/{version}/rollback [post]
+func (h *BookHandler) RollbackBookFile(c *gin.Context) {
+    version, ok := versionParam(c)
+    if !ok {
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    restored, err := h.bookService.RollbackBookFile(ctx, c.Param("id"), version, c.GetString("user_id"))
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.MapBookFileToResponse(restored, true))
+}
+
+// versionParam parses the version path parameter, writing the error response
+// when ok is false
+func versionParam(c *gin.Context) (version int, ok bool) {
+    version, err := strconv.Atoi(c.Param("version"))
+    if err != nil || version < 1 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive number"})
+        return 0, false
+    }
+    return version, true
 }
 
 // GetAllBooks godoc
 // @Summary Get all books
-// @Description Get all books ordered by save count with pagination
+// @Description Get books with optional full-text search, category and page count filters. Books whose page count is unknown are left out when filtering by it. Unless sort_by is given, results are ranked by relevance when searching, otherwise ordered by save count
 // @Tags books
 // @Produce json
 // @Security BearerAuth
+// @Param search query string false "Search in title, author names and description (prefix matching)"
+// @Param category_id query string false "Filter by category ID"
+// @Param min_pages query int false "Only books with at least this many pages"
+// @Param max_pages query int false "Only books with at most this many pages"
+// @Param tags query string false "Comma separated tags, e.g. beginner,exam-prep"
+// @Param match query string false "Whether books need all of the tags or any of them (default: all)" Enums(all, any)
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param sort_by query string false "Sort field" Enums(created_at, updated_at, save_count, title, like_count, download_count, rating, page_count)
+// @Param order query string false "Sort order (default: desc, title defaults to asc)" Enums(asc, desc)
 // @Success 200 {object} dto.BookListResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /books [get]
 func (h *BookHandler) GetAllBooks(c *gin.Context) {
-    // Parse pagination parameters
-    page := 1
-    pageSize := 20
-
-    if p := c.Query("page"); p != "" {
-        if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
-            page = parsedPage
-        }
+    var filter dto.BookFilterRequest
+    if err := c.ShouldBindQuery(&filter); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
     }
 
-    if ps := c.Query("page_size"); ps != "" {
-        if parsedSize, err := strconv.Atoi(ps); err == nil && parsedSize > 0 {
-            pageSize = parsedSize
-        }
-    }
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
 
-    // Get paginated books
-    books, total, err := h.bookService.GetAllBooksPaginated(page, pageSize)
+    books, total, err := h.bookService.SearchBooks(ctx, &filter)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
-    // Build response
-    response := dto.BookListResponse{
-        Books:      make([]dto.BookResponse, len(books)),
-        Pagination: utils.BuildPaginationResponse(page, pageSize, total),
+    bookResponses, err := h.bookService.MapBooksForUser(ctx, c.GetString("user_id"), books)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
     }
 
-    for i, book := range books {
-        response.Books[i] = dto.BookResponse{
-            ID:           book.ID,
-            Title:        book.Title,
-            Description:  book.Description,
-            PDFFile:      book.PDFFile,
-            CategoryID:   book.CategoryID,
-            CategoryName: book.CategoryName,
-            OwnerID:      book.OwnerID,
-            LikeCount:    book.LikeCount,
-            DislikeCount: book.DislikeCount,
-            SaveCount:    book.SaveCount,
-            CreatedAt:    book.CreatedAt,
-            UpdatedAt:    book.UpdatedAt,
-        }
+    response := dto.BookListResponse{
+        Books:      bookResponses,
+        Pagination: utils.BuildPaginationResponse(filter.Page, filter.PageSize, total),
     }
 
     c.JSON(http.StatusOK, response)
@@ -156,9 +431,12 @@ func (h *BookHandler) GetAllBooks(c *gin.Context) {
 func (h *BookHandler) GetBook(c *gin.Context) {
     id := c.Param("id")
 
-    book, err := h.bookService.GetBook(id)
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    book, err := h.bookService.GetBook(ctx, id)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
     if book == nil {
@@ -166,35 +444,52 @@ func (h *BookHandler) GetBook(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, book)
+    responses, err := h.bookService.MapBooksForUser(ctx, c.GetString("user_id"), []*models.BookWithCategory{book})
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, responses[0])
 }
 
 // UpdateBook godoc
 // @Summary Update a book (Owner only)
-// @Description Update book information (Owner only)
+// @Description Update book information (Owner only). author_ids and tags replace the authors and tags of the book, omitting them keeps the current ones. Send a multipart form instead of JSON to replace the cover image as well.
 // @Tags books
-// @Accept json
+// @Accept json,multipart/form-data
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Book ID"
 // @Param request body dto.UpdateBookRequest true "Update Book Request"
+// @Param cover_image formData file false "New cover image (JPEG, PNG or WebP), multipart requests only"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Router /books/{id} [put]
 func (h *BookHandler) UpdateBook(c *gin.Context) {
     id := c.Param("id")
     var req dto.UpdateBookRequest
 
-    if err := c.ShouldBindJSON(&req); err != nil {
+    if err := c.ShouldBind(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
+    coverImage, ok := h.receiveCover(c)
+    if !ok {
+        return
+    }
+
     userID := c.GetString("user_id")
-    if err := h.bookService.UpdateBook(id, &req, userID); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.UpdateBook(ctx, id, &req, coverImage, userID); err != nil {
+        h.discardCover(c, coverImage)
+        utils.HandleError(c, err)
         return
     }
 
@@ -203,37 +498,83 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 
 // DeleteBook godoc
 // @Summary Delete a book (Owner only)
-// @Description Delete a book (Owner only)
+// @Description Move a book to the trash (Owner only). It can be restored until the retention period has passed, after which the book and its file are removed permanently
 // @Tags books
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Book ID"
 // @Success 200 {object} map[string]string
 // @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Router /books/{id} [delete]
 func (h *BookHandler) DeleteBook(c *gin.Context) {
     id := c.Param("id")
     userID := c.GetString("user_id")
 
-    if err := h.bookService.DeleteBook(id, userID); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.DeleteBook(ctx, id, userID); err != nil {
+        utils.HandleError(c, err)
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "Book deleted successfully"})
 }
 
+// RestoreBook godoc
+// @Summary Restore a deleted book (Owner only)
+// @Description Restore a deleted book that has not been purged yet (Owner only)
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} dto.BookResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/restore [post]
+func (h *BookHandler) RestoreBook(c *gin.Context) {
+    id := c.Param("id")
+    userID := c.GetString("user_id")
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    book, err := h.bookService.RestoreBook(ctx, id, userID)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+    if book == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+        return
+    }
+
+    responses, err := h.bookService.MapBooksForUser(ctx, userID, []*models.BookWithCategory{book})
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, responses[0])
+}
+
 // GetBooksByCategory godoc
 // @Summary Get books by category
-// @Description Get all books in a specific category ordered by save count with pagination
+// @Description Get all books in a specific category with pagination, ordered by save count unless sort_by is given
 // @Tags books
 // @Produce json
 // @Security BearerAuth
 // @Param category_id query string true "Category ID"
+// @Param include_descendants query bool false "Include books of all nested subcategories"
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param sort_by query string false "Sort field" Enums(created_at, updated_at, save_count, title, like_count, download_count, rating, page_count)
+// @Param order query string false "Sort order (default: desc, title defaults to asc)" Enums(asc, desc)
 // @Success 200 {object} dto.BookListResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /books/category [get]
 func (h *BookHandler) GetBooksByCategory(c *gin.Context) {
@@ -243,50 +584,43 @@ func (h *BookHandler) GetBooksByCategory(c *gin.Context) {
         return
     }
 
-    // Parse pagination parameters
+    var pagination dto.PaginationRequest
+    if err := c.ShouldBindQuery(&pagination); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     page := 1
     pageSize := 20
-
-    if p := c.Query("page"); p != "" {
-        if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
-            page = parsedPage
-        }
+    if pagination.Page > 0 {
+        page = pagination.Page
     }
-
-    if ps := c.Query("page_size"); ps != "" {
-        if parsedSize, err := strconv.Atoi(ps); err == nil && parsedSize > 0 {
-            pageSize = parsedSize
-        }
+    if pagination.PageSize > 0 {
+        pageSize = pagination.PageSize
     }
 
     // Get paginated books
-    books, total, err := h.bookService.GetBooksByCategoryPaginated(categoryID, page, pageSize)
+    includeDescendants := c.Query("include_descendants") == "true"
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    books, total, err := h.bookService.GetBooksByCategoryPaginated(ctx, categoryID, page, pageSize,
+        pagination.SortBy, pagination.Order, includeDescendants)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
-    // Build response
-    response := dto.BookListResponse{
-        Books:      make([]dto.BookResponse, len(books)),
-        Pagination: utils.BuildPaginationResponse(page, pageSize, total),
+    bookResponses, err := h.bookService.MapBooksForUser(ctx, c.GetString("user_id"), books)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
     }
 
-    for i, book := range books {
-        response.Books[i] = dto.BookResponse{
-            ID:           book.ID,
-            Title:        book.Title,
-            Description:  book.Description,
-            PDFFile:      book.PDFFile,
-            CategoryID:   book.CategoryID,
-            CategoryName: book.CategoryName,
-            OwnerID:      book.OwnerID,
-            LikeCount:    book.LikeCount,
-            DislikeCount: book.DislikeCount,
-            SaveCount:    book.SaveCount,
-            CreatedAt:    book.CreatedAt,
-            UpdatedAt:    book.UpdatedAt,
-        }
+    response := dto.BookListResponse{
+        Books:      bookResponses,
+        Pagination: utils.BuildPaginationResponse(page, pageSize, total),
     }
 
     c.JSON(http.StatusOK, response)
@@ -301,13 +635,18 @@ func (h *BookHandler) GetBooksByCategory(c *gin.Context) {
 // @Param id path string true "Book ID"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Router /books/{id}/save [post]
 func (h *BookHandler) SaveBook(c *gin.Context) {
     bookID := c.Param("id")
     userID := c.GetString("user_id")
 
-    if err := h.bookService.SaveBook(userID, bookID); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.SaveBook(ctx, userID, bookID); err != nil {
+        utils.HandleError(c, err)
         return
     }
 
@@ -328,8 +667,11 @@ func (h *BookHandler) UnsaveBook(c *gin.Context) {
     bookID := c.Param("id")
     userID := c.GetString("user_id")
 
-    if err := h.bookService.UnsaveBook(userID, bookID); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.UnsaveBook(ctx, userID, bookID); err != nil {
+        utils.HandleError(c, err)
         return
     }
 
@@ -338,23 +680,41 @@ func (h *BookHandler) UnsaveBook(c *gin.Context) {
 
 // GetSavedBooks godoc
 // @Summary Get saved books (Member only)
-// @Description Get all books saved by the user (Member only)
+// @Description Get all books saved by the user, most recently saved first unless sort_by is given (Member only)
 // @Tags books
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} dto.SavedBookResponse
+// @Param sort_by query string false "Sort field" Enums(created_at, updated_at, save_count, title, like_count, download_count, rating, page_count)
+// @Param order query string false "Sort order (default: desc, title defaults to asc)" Enums(asc, desc)
+// @Success 200 {array} dto.BookResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /books/saved [get]
 func (h *BookHandler) GetSavedBooks(c *gin.Context) {
     userID := c.GetString("user_id")
 
-    books, err := h.bookService.GetSavedBooks(userID)
+    var pagination dto.PaginationRequest
+    if err := c.ShouldBindQuery(&pagination); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    books, err := h.bookService.GetSavedBooks(ctx, userID, pagination.SortBy, pagination.Order)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    responses, err := h.bookService.MapBooksForUser(ctx, userID, books)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
-    c.JSON(http.StatusOK, books)
+    c.JSON(http.StatusOK, responses)
 }
 
 // LikeBook godoc
@@ -366,8 +726,9 @@ func (h *BookHandler) GetSavedBooks(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Book ID"
 // @Param request body dto.LikeRequest true "Like Request"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} dto.LikeResponse
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
 // @Router /books/{id}/like [post]
 func (h *BookHandler) LikeBook(c *gin.Context) {
     bookID := c.Param("id")
@@ -379,17 +740,48 @@ func (h *BookHandler) LikeBook(c *gin.Context) {
         return
     }
 
-    if err := h.bookService.LikeBook(userID, bookID, req.IsLike); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.bookService.LikeBook(ctx, userID, bookID, req.IsLike)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, response)
+}
+
+// RemoveLike godoc
+// @Summary Remove like or dislike from a book
+// @Description Clear the current user's like or dislike on a book
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} dto.LikeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/like [delete]
+func (h *BookHandler) RemoveLike(c *gin.Context) {
+    bookID := c.Param("id")
+    userID := c.GetString("user_id")
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.bookService.RemoveLike(ctx, userID, bookID)
+    if err != nil {
+        utils.HandleError(c, err)
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Like updated successfully"})
+    c.JSON(http.StatusOK, response)
 }
 
 // AddComment godoc
 // @Summary Add a comment
-// @Description Add a comment to a book (Member can comment, Owner can reply)
+// @Description Add a comment to a book (Member can comment, Owner can reply). A reply's parent must belong to the same book and replies are limited to a maximum nesting depth
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -398,6 +790,7 @@ func (h *BookHandler) LikeBook(c *gin.Context) {
 // @Param request body dto.CreateCommentRequest true "Comment Request"
 // @Success 201 {object} dto.CommentResponse
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
 // @Router /books/{id}/comments [post]
 func (h *BookHandler) AddComment(c *gin.Context) {
     bookID := c.Param("id")
@@ -409,9 +802,12 @@ func (h *BookHandler) AddComment(c *gin.Context) {
         return
     }
 
-    comment, err := h.bookService.AddComment(userID, bookID, req.Content, req.ParentID)
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    comment, err := h.bookService.AddComment(ctx, userID, bookID, req.Content, req.ParentID)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
@@ -420,74 +816,266 @@ func (h *BookHandler) AddComment(c *gin.Context) {
 
 // GetComments godoc
 // @Summary Get book comments
-// @Description Get all comments for a book
+// @Description Get comments of a book as nested threads with reply counts, paginated on top-level threads. Use format=flat for a depth-first flat list
 // @Tags comments
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Book ID"
-// @Success 200 {array} dto.CommentResponse
+// @Param format query string false "Response format (default: tree)" Enums(tree, flat)
+// @Param sort query string false "Comment order (default: newest). Replies are oldest first unless sorted by top" Enums(newest, oldest, top)
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} dto.CommentListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} map[string]string
 // @Router /books/{id}/comments [get]
 func (h *BookHandler) GetComments(c *gin.Context) {
     bookID := c.Param("id")
 
-    comments, err := h.bookService.GetComments(bookID)
+    format := c.DefaultQuery("format", "tree")
+    if format != "tree" && format != "flat" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "format must be tree or flat"})
+        return
+    }
+
+    sort := c.DefaultQuery("sort", "newest")
+    if sort != "newest" && sort != "oldest" && sort != "top" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be newest, oldest or top"})
+        return
+    }
+
+    var pagination dto.PaginationRequest
+    if err := c.ShouldBindQuery(&pagination); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    comments, err := h.bookService.GetComments(ctx, bookID, pagination.Page, pagination.PageSize, sort, format == "flat")
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
     c.JSON(http.StatusOK, comments)
 }
 
-// CreateCategory godoc
-// @Summary Create a category (Owner only)
-// @Description Create a new category (Owner only)
-// @Tags categories
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Edit own comment within the configured edit window after posting
+// @Tags comments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body dto.CreateCategoryRequest true "Category Request"
-// @Success 201 {object} dto.CategoryResponse
+// @Param id path string true "Book ID"
+// @Param commentId path string true "Comment ID"
+// @Param request body dto.UpdateCommentRequest true "Update Comment Request"
+// @Success 200 {object} models.Comment
 // @Failure 400 {object} map[string]string
-// @Router /categories [post]
-func (h *BookHandler) CreateCategory(c *gin.Context) {
-    var req dto.CreateCategoryRequest
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/comments/{commentId} [put]
+func (h *BookHandler) UpdateComment(c *gin.Context) {
+    bookID := c.Param("id")
+    commentID := c.Param("commentId")
+    userID := c.GetString("user_id")
+
+    var req dto.UpdateCommentRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    category, err := h.bookService.CreateCategory(&req)
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    comment, err := h.bookService.UpdateComment(ctx, userID, bookID, commentID, req.Content)
     if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Delete own comment, or any comment on a book you own. Comments with replies are replaced by a "[deleted]" placeholder
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/comments/{commentId} [delete]
+func (h *BookHandler) DeleteComment(c *gin.Context) {
+    bookID := c.Param("id")
+    commentID := c.Param("commentId")
+    userID := c.GetString("user_id")
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.DeleteComment(ctx, userID, bookID, commentID); err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// LikeComment godoc
+// @Summary Like or dislike a comment
+// @Description Add or change like or dislike on a comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param request body dto.LikeRequest true "Like Request"
+// @Success 200 {object} dto.CommentLikeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /comments/{id}/like [post]
+func (h *BookHandler) LikeComment(c *gin.Context) {
+    commentID := c.Param("id")
+    userID := c.GetString("user_id")
+
+    var req dto.LikeRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.bookService.LikeComment(ctx, userID, commentID, req.IsLike)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, response)
+}
+
+// RemoveCommentLike godoc
+// @Summary Remove like or dislike from a comment
+// @Description Clear the current user's like or dislike on a comment
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Success 200 {object} dto.CommentLikeResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /comments/{id}/like [delete]
+func (h *BookHandler) RemoveCommentLike(c *gin.Context) {
+    commentID := c.Param("id")
+    userID := c.GetString("user_id")
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    response, err := h.bookService.RemoveCommentLike(ctx, userID, commentID)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, response)
+}
+
+// CreateCategory godoc
+// @Summary Create a category (Owner only)
+// @Description Create a new category (Owner only)
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateCategoryRequest true "Category Request"
+// @Success 201 {object} dto.CategoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /categories [post]
+func (h *BookHandler) CreateCategory(c *gin.Context) {
+    var req dto.CreateCategoryRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    category, err := h.bookService.CreateCategory(ctx, &req)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
     c.JSON(http.StatusCreated, category)
 }
 
+// UpdateCategory godoc
+// @Summary Update a category (Owner only)
+// @Description Update category name and description. Category names must be unique. parent_id moves the category below another one, null or an empty string moves it to the top level, and leaving it out keeps the current parent (Owner only)
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param request body dto.UpdateCategoryRequest true "Update Category Request"
+// @Success 200 {object} dto.CategoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /categories/{id} [put]
+func (h *BookHandler) UpdateCategory(c *gin.Context) {
+    categoryID := c.Param("id")
+
+    var req dto.UpdateCategoryRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    category, err := h.bookService.UpdateCategory(ctx, categoryID, &req)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, category)
+}
+
 // DeleteCategory godoc
 // @Summary Delete a category (Owner only)
-// @Description Delete a category if it has no books (Owner only)
+// @Description Delete a category if it has no subcategories and no books, including deleted books that have not been purged yet (Owner only)
 // @Tags categories
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Category ID"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
 // @Failure 403 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Router /categories/{id} [delete]
 func (h *BookHandler) DeleteCategory(c *gin.Context) {
     categoryID := c.Param("id")
 
-    if err := h.bookService.DeleteCategory(categoryID); err != nil {
-        if err.Error() == "category not found" {
-            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-            return
-        }
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.DeleteCategory(ctx, categoryID); err != nil {
+        utils.HandleError(c, err)
         return
     }
 
@@ -496,41 +1084,433 @@ func (h *BookHandler) DeleteCategory(c *gin.Context) {
 
 // GetAllCategories godoc
 // @Summary Get all categories
-// @Description Get all available categories
+// @Description Get all available categories with the number of books in each
 // @Tags categories
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} dto.CategoryResponse
+// @Success 200 {object} dto.CategoryListResponse
 // @Failure 500 {object} map[string]string
 // @Router /categories [get]
 func (h *BookHandler) GetAllCategories(c *gin.Context) {
-    categories, err := h.bookService.GetAllCategories()
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    categories, err := h.bookService.GetAllCategories(ctx)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
 
-    c.JSON(http.StatusOK, categories)
+    c.JSON(http.StatusOK, dto.CategoryListResponse{
+        Categories: utils.MapCategoriesWithCountToResponse(categories),
+    })
+}
+
+// GetCategoryTree godoc
+// @Summary Get category tree
+// @Description Get all categories nested under their parent categories
+// @Tags categories
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CategoryTreeResponse
+// @Failure 500 {object} map[string]string
+// @Router /categories/tree [get]
+func (h *BookHandler) GetCategoryTree(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    tree, err := h.bookService.GetCategoryTree(ctx)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, dto.CategoryTreeResponse{Categories: tree})
+}
+
+// CreateAuthor godoc
+// @Summary Create an author (Owner only)
+// @Description Create an author that books can be linked to. Author names must be unique (Owner only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAuthorRequest true "Create Author Request"
+// @Success 201 {object} dto.AuthorResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /authors [post]
+func (h *BookHandler) CreateAuthor(c *gin.Context) {
+    var req dto.CreateAuthorRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    author, err := h.bookService.CreateAuthor(ctx, &req)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, utils.MapAuthorToResponse(author))
+}
+
+// GetAllAuthors godoc
+// @Summary Get all authors
+// @Description Get authors ordered by name with the number of books of each
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} dto.AuthorListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /authors [get]
+func (h *BookHandler) GetAllAuthors(c *gin.Context) {
+    var pagination dto.PaginationRequest
+    if err := c.ShouldBindQuery(&pagination); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    page := 1
+    pageSize := 20
+    if pagination.Page > 0 {
+        page = pagination.Page
+    }
+    if pagination.PageSize > 0 {
+        pageSize = pagination.PageSize
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    authors, total, err := h.bookService.GetAllAuthors(ctx, page, pageSize)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, dto.AuthorListResponse{
+        Authors:    utils.MapAuthorsWithCountToResponse(authors),
+        Pagination: utils.BuildPaginationResponse(page, pageSize, total),
+    })
+}
+
+// GetAuthor godoc
+// @Summary Get an author by ID
+// @Description Get an author with the number of their books
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Author ID"
+// @Success 200 {object} dto.AuthorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /authors/{id} [get]
+func (h *BookHandler) GetAuthor(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    author, err := h.bookService.GetAuthor(ctx, c.Param("id"))
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, author)
+}
+
+// UpdateAuthor godoc
+// @Summary Update an author (Owner only)
+// @Description Update the name and bio of an author. The new name shows up in the author's books and in book search (Owner only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Author ID"
+// @Param request body dto.UpdateAuthorRequest true "Update Author Request"
+// @Success 200 {object} dto.AuthorResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /authors/{id} [put]
+func (h *BookHandler) UpdateAuthor(c *gin.Context) {
+    var req dto.UpdateAuthorRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    author, err := h.bookService.UpdateAuthor(ctx, c.Param("id"), &req)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, author)
+}
+
+// DeleteAuthor godoc
+// @Summary Delete an author (Owner only)
+// @Description Delete an author and unlink it from its books. The books are kept (Owner only)
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Author ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /authors/{id} [delete]
+func (h *BookHandler) DeleteAuthor(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    if err := h.bookService.DeleteAuthor(ctx, c.Param("id")); err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "author deleted successfully"})
+}
+
+// GetBooksByAuthor godoc
+// @Summary Get books by author
+// @Description Get the books of an author with pagination, newest first unless sort_by is given
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Author ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param sort_by query string false "Sort field" Enums(created_at, updated_at, save_count, title, like_count, download_count, rating, page_count)
+// @Param order query string false "Sort order (default: desc, title defaults to asc)" Enums(asc, desc)
+// @Success 200 {object} dto.BookListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} map[string]string
+// @Router /authors/{id}/books [get]
+func (h *BookHandler) GetBooksByAuthor(c *gin.Context) {
+    var pagination dto.PaginationRequest
+    if err := c.ShouldBindQuery(&pagination); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    page := 1
+    pageSize := 20
+    if pagination.Page > 0 {
+        page = pagination.Page
+    }
+    if pagination.PageSize > 0 {
+        pageSize = pagination.PageSize
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    books, total, err := h.bookService.GetBooksByAuthor(ctx, c.Param("id"), page, pageSize,
+        pagination.SortBy, pagination.Order)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    bookResponses, err := h.bookService.MapBooksForUser(ctx, c.GetString("user_id"), books)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, dto.BookListResponse{
+        Books:      bookResponses,
+        Pagination: utils.BuildPaginationResponse(page, pageSize, total),
+    })
+}
+
+// GetTags godoc
+// @Summary Autocomplete tags
+// @Description Get the tags in use starting with the prefix together with the number of books having them, the most used first. Without a prefix the most used tags are returned
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param prefix query string false "Start of the tag name, case insensitive"
+// @Param limit query int false "Maximum number of tags (default: 10, max: 50)"
+// @Success 200 {object} dto.TagListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tags [get]
+func (h *BookHandler) GetTags(c *gin.Context) {
+    var req dto.TagSearchRequest
+    if err := c.ShouldBindQuery(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    tags, err := h.bookService.GetTags(ctx, req.Prefix, req.Limit)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, dto.TagListResponse{Tags: utils.MapTagsToResponse(tags)})
 }
 
 // DownloadBook godoc
-// @Summary Download a book PDF
-// @Description Download the PDF file of a book
+// @Summary Download a book file
+// @Description Download the file of a book with the Content-Type and extension of its format. Single and multiple byte ranges are supported for resuming downloads, as are conditional requests with If-None-Match, If-Modified-Since and If-Range. The ETag is the SHA-256 of the file. A download is counted once the whole file has been sent, in a plain response or in a single range covering every byte; partial reads and the remaining ranges of resumed downloads are not counted.
 // @Tags books
-// @Produce application/pdf
+// @Produce application/pdf,application/epub+zip,image/vnd.djvu,text/plain
 // @Security BearerAuth
 // @Param id path string true "Book ID"
-// @Success 200 {file} binary "PDF file"
+// @Param Range header string false "Byte ranges, e.g. bytes=0-1023"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {file} binary "Book file"
+// @Success 206 {file} binary "Requested byte ranges"
+// @Success 304 "Not modified"
 // @Failure 404 {object} map[string]string
+// @Failure 416 "Requested range not satisfiable"
 // @Failure 500 {object} map[string]string
 // @Router /books/{id}/download [get]
 func (h *BookHandler) DownloadBook(c *gin.Context) {
-    bookID := c.Param("id")
+    h.serveDownload(c, c.Param("id"), c.GetString("user_id"))
+}
+
+// CreateDownloadLink godoc
+// @Summary Create a signed download link
+// @Description Create a short-lived link to download a book without an Authorization header, e.g. from an <a href>. The link is bound to the current user and the book, and downloads through it are recorded against the user.
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 201 {object} dto.DownloadLinkResponse
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/download-link [post]
+func (h *BookHandler) CreateDownloadLink(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    token, expiresAt, err := h.bookService.CreateDownloadLink(ctx, c.Param("id"), c.GetString("user_id"))
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, dto.DownloadLinkResponse{
+        URL:       downloadLinkPath + token,
+        ExpiresAt: expiresAt,
+    })
+}
+
+// DownloadFile godoc
+// @Summary Download a book through a signed link
+// @Description Download the file of a book with a token from POST /books/{id}/download-link. No Authorization header is needed. Links stop working when the user logs out or refreshes their tokens. Ranges and conditional requests are supported as for regular downloads.
+// @Tags books
+// @Produce application/pdf,application/epub+zip,image/vnd.djvu,text/plain
+// @Param token path string true "Download token"
+// @Param Range header string false "Byte ranges, e.g. bytes=0-1023"
+// @Success 200 {file} binary "Book file"
+// @Success 206 {file} binary "Requested byte ranges"
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} map[string]string
+// @Failure 416 "Requested range not satisfiable"
+// @Router /files/{token} [get]
+func (h *BookHandler) DownloadFile(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    claims, err := h.bookService.ResolveDownloadLink(ctx, c.Param("token"))
+    cancel()
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    // The token is a credential, keep it out of caches and Referer headers
+    c.Header("Cache-Control", "private, no-store")
+    c.Header("Referrer-Policy", "no-referrer")
+    h.serveDownload(c, claims.BookID, claims.UserID)
+}
+
+// serveDownload sends the file of the book as an attachment and records the
+// download against userID
+func (h *BookHandler) serveDownload(c *gin.Context, bookID, userID string) {
+    h.serveBookFile(c, bookID, "attachment", func(ctx context.Context, book *models.BookWithCategory, object *storage.Object) {
+        if !countsAsDownload(c, object.Size) {
+            return
+        }
+
+        // Record download activity. A failure here must not affect the response.
+        if err := h.bookService.RecordDownload(ctx, userID, book.ID, c.ClientIP(), c.Request.UserAgent()); err != nil {
+            utils.LogError(err, "Failed to record download", map[string]interface{}{
+                "book_id": book.ID,
+                "user_id": userID,
+            })
+        }
+
+        utils.LogInfo("Book downloaded", map[string]interface{}{
+            "book_id": book.ID,
+            "user_id": userID,
+            "title":   book.Title,
+        })
+    })
+}
+
+// ReadBook godoc
+// @Summary Read a book in the browser
+// @Description Stream the file of a book for display in the web reader. Ranges and conditional requests are supported as for downloads. Opening a book is counted as a view, not as a download: a view is recorded for every request that starts at the first byte of the file.
+// @Tags books
+// @Produce application/pdf,application/epub+zip,image/vnd.djvu,text/plain
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param Range header string false "Byte ranges, e.g. bytes=0-1023"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {file} binary "Book file"
+// @Success 206 {file} binary "Requested byte ranges"
+// @Success 304 "Not modified"
+// @Failure 404 {object} map[string]string
+// @Failure 416 "Requested range not satisfiable"
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/read [get]
+func (h *BookHandler) ReadBook(c *gin.Context) {
+    h.serveBookFile(c, c.Param("id"), "inline", func(ctx context.Context, book *models.BookWithCategory, object *storage.Object) {
+        if !countsAsView(c) {
+            return
+        }
+
+        userID := c.GetString("user_id")
+        if err := h.bookService.RecordView(ctx, userID, book.ID, c.ClientIP(), c.Request.UserAgent()); err != nil {
+            utils.LogError(err, "Failed to record view", map[string]interface{}{
+                "book_id": book.ID,
+                "user_id": userID,
+            })
+        }
+    })
+}
+
+// serveBookFile sends the current file of the book with the given
+// Content-Disposition. served is called once the response is complete, with
+// a context that is not bound to the request deadline.
+func (h *BookHandler) serveBookFile(c *gin.Context, bookID, disposition string, served func(ctx context.Context, book *models.BookWithCategory, object *storage.Object)) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
 
     // Get book details
-    book, err := h.bookService.GetBook(bookID)
+    book, err := h.bookService.GetBook(ctx, bookID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        utils.HandleError(c, err)
         return
     }
     if book == nil {
@@ -538,33 +1518,310 @@ func (h *BookHandler) DownloadBook(c *gin.Context) {
         return
     }
 
-    // Build file path
-    filePath := filepath.Join(h.cfg.Upload.Path, book.PDFFile)
+    hash, err := h.bookService.FileHash(c.Request.Context(), book)
+    if errors.Is(err, storage.ErrNotFound) {
+        // Reported by serveFile below
+        hash, err = "", nil
+    }
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    h.serveFile(c, book.PDFFile, hash, formatOf(book.Format), book.Title, disposition, func(ctx context.Context, object *storage.Object) {
+        served(ctx, book, object)
+    })
+}
+
+// serveFile sends the stored file under key as name plus the extension of its
+// format, answering range and conditional requests. hash becomes the ETag
+// when known. served, if not nil, is called once the response is complete,
+// with a context that is not bound to the request deadline.
+func (h *BookHandler) serveFile(c *gin.Context, key, hash string, bookFormat *format.Format, name, disposition string, served func(ctx context.Context, object *storage.Object)) {
+    object, err := h.files.Stat(c.Request.Context(), key)
+    if errors.Is(err, storage.ErrNotFound) {
+        utils.LogError(err, "Book file not found in storage", map[string]interface{}{
+            "book_id": c.Param("id"),
+            "file":    key,
+        })
+        c.JSON(http.StatusNotFound, gin.H{"error": "Book file not found"})
+        return
+    }
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    // The request deadline only bounds database work. A client that goes away
+    // ends the transfer through failing writes instead.
+    streamCtx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+    defer cancel()
+    content := storage.NewObjectReader(streamCtx, h.files, object)
+    defer content.Close()
+
+    if hash != "" {
+        c.Header("ETag", `"`+hash+`"`)
+    }
+    c.Header("Content-Type", bookFormat.ContentType)
+    c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name + bookFormat.Extension}))
+
+    // ServeContent answers Range, If-Range, If-None-Match and If-Modified-Since
+    http.ServeContent(c.Writer, c.Request, "", object.ModTime, content)
+
+    if served != nil {
+        served(streamCtx, object)
+    }
+}
+
+// formatOf returns the format recorded for a book or file version. Unknown
+// names fall back to PDF, the only format accepted before formats were recorded.
+func formatOf(name string) *format.Format {
+    if f, ok := format.ByName(name); ok {
+        return f
+    }
+    return format.PDF
+}
+
+// countsAsDownload reports whether the response just served is a download:
+// the complete file was sent, either as a whole or as a single range running
+// from the first to the last byte. Readers fetching parts of the file and
+// the later ranges of a resumed download are not counted.
+func countsAsDownload(c *gin.Context, size int64) bool {
+    if c.Request.Method != http.MethodGet {
+        return false
+    }
+
+    switch c.Writer.Status() {
+    case http.StatusOK:
+        return int64(c.Writer.Size()) == size
+    case http.StatusPartialContent:
+        return servedWholeFile(c, size)
+    default:
+        return false
+    }
+}
+
+// servedWholeFile reports whether a partial response sent every byte of the
+// file. Only single ranges carry a Content-Range header; multipart responses
+// never count.
+func servedWholeFile(c *gin.Context, size int64) bool {
+    var start, end, total int64
+    _, err := fmt.Sscanf(c.Writer.Header().Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+    if err != nil {
+        return false
+    }
+    return start == 0 && end == size-1 && total == size && int64(c.Writer.Size()) == size
+}
+
+// countsAsView reports whether the response just served opened the book in
+// the reader. Readers such as pdf.js start with a plain request they abort
+// once they know ranges are supported, so unlike downloads the file does not
+// have to be sent completely. The ranges they fetch afterwards are not counted.
+func countsAsView(c *gin.Context) bool {
+    if c.Request.Method != http.MethodGet {
+        return false
+    }
+
+    switch c.Writer.Status() {
+    case http.StatusOK:
+        return true
+    case http.StatusPartialContent:
+        return firstRangeStartsAtZero(c)
+    default:
+        return false
+    }
+}
+
+func firstRangeStartsAtZero(c *gin.Context) bool {
+    ranges, ok := strings.CutPrefix(c.GetHeader("Range"), "bytes=")
+    if !ok {
+        return false
+    }
+    first, _, _ := strings.Cut(ranges, ",")
+    start, _, _ := strings.Cut(first, "-")
+    return strings.TrimSpace(start) == "0"
+}
+
+// coverCacheControl lets clients reuse covers for an hour before revalidating
+// them with their ETag
+const coverCacheControl = "private, max-age=3600"
+
+// GetBookCover godoc
+// @Summary Get the cover of a book
+// @Description Get a cover thumbnail of a book as JPEG. Books without an uploaded cover get a PNG placeholder showing the initials of the title on a color derived from the book ID. Responses carry an ETag and may be cached for an hour; conditional requests with If-None-Match are answered with 304.
+// @Tags books
+// @Produce image/jpeg,image/png
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param size query string false "Thumbnail size (default: small)" Enums(small, medium, large)
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {file} binary "Cover image"
+// @Success 304 "Not modified"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/cover [get]
+func (h *BookHandler) GetBookCover(c *gin.Context) {
+    size, ok := cover.SizeByName(c.DefaultQuery("size", cover.Small.Name))
+    if !ok {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "size must be one of " + cover.SizeNames()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
 
-    // Check if file exists
-    if _, err := os.Stat(filePath); os.IsNotExist(err) {
-        utils.LogError(err, "PDF file not found on disk", map[string]interface{}{
-            "book_id":   bookID,
-            "file_path": filePath,
+    book, err := h.bookService.GetBook(ctx, c.Param("id"))
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+    if book == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+        return
+    }
+
+    if book.CoverImage != "" {
+        key := cover.Key(book.CoverImage, size)
+        object, err := h.files.Stat(c.Request.Context(), key)
+        if err == nil {
+            content := storage.NewObjectReader(c.Request.Context(), h.files, object)
+            defer content.Close()
+
+            // Covers are replaced under a new key prefix, so the key identifies the content
+            c.Header("ETag", `"`+strings.ReplaceAll(key, "/", "-")+`"`)
+            c.Header("Cache-Control", coverCacheControl)
+            c.Header("Content-Type", cover.ContentType)
+            http.ServeContent(c.Writer, c.Request, "", object.ModTime, content)
+            return
+        }
+        if !errors.Is(err, storage.ErrNotFound) {
+            utils.HandleError(c, err)
+            return
+        }
+        // A missing thumbnail is logged and replaced by the placeholder
+        utils.LogError(err, "Cover thumbnail not found in storage", map[string]interface{}{
+            "book_id": book.ID,
+            "file":    key,
         })
-        c.JSON(http.StatusNotFound, gin.H{"error": "PDF file not found"})
+    }
+
+    // The placeholder depends on the title, which the ETag must follow
+    var placeholder bytes.Buffer
+    if err := cover.EncodePlaceholder(&placeholder, cover.Placeholder(book.Title, book.ID, size)); err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+    digest := sha256.Sum256(placeholder.Bytes())
+    c.Header("ETag", `"placeholder-`+hex.EncodeToString(digest[:8])+`"`)
+    c.Header("Cache-Control", coverCacheControl)
+    c.Header("Content-Type", cover.PlaceholderContentType)
+    http.ServeContent(c.Writer, c.Request, "", book.UpdatedAt, bytes.NewReader(placeholder.Bytes()))
+}
+
+// GetReadingProgress godoc
+// @Summary Get reading progress
+// @Description Get the last page the current user read of a book
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} dto.ReadingProgressResponse
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/progress [get]
+func (h *BookHandler) GetReadingProgress(c *gin.Context) {
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    progress, err := h.bookService.GetReadingProgress(ctx, c.GetString("user_id"), c.Param("id"))
+    if err != nil {
+        utils.HandleError(c, err)
         return
     }
 
-    // Log download activity
+    c.JSON(http.StatusOK, utils.MapReadingProgressToResponse(progress))
+}
+
+// UpdateReadingProgress godoc
+// @Summary Save reading progress
+// @Description Store the last page the current user read of a book, replacing the previous progress
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param request body dto.UpdateReadingProgressRequest true "Last page read"
+// @Success 200 {object} dto.ReadingProgressResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /books/{id}/progress [put]
+func (h *BookHandler) UpdateReadingProgress(c *gin.Context) {
+    var req dto.UpdateReadingProgressRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    progress, err := h.bookService.SaveReadingProgress(ctx, c.GetString("user_id"), c.Param("id"), req.Page)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, utils.MapReadingProgressToResponse(progress))
+}
+
+// GetBookDownloads godoc
+// @Summary Get download history of a book (Owner only)
+// @Description Get paginated download history of a book, most recent first (Owner of the book only)
+// @Tags books
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} dto.DownloadListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/downloads [get]
+func (h *BookHandler) GetBookDownloads(c *gin.Context) {
+    bookID := c.Param("id")
     userID := c.GetString("user_id")
-    utils.LogInfo("Book downloaded", map[string]interface{}{
-        "book_id": bookID,
-        "user_id": userID,
-        "title":   book.Title,
-    })
 
-    // Set headers for file download
-    c.Header("Content-Description", "File Transfer")
-    c.Header("Content-Transfer-Encoding", "binary")
-    c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", book.Title))
-    c.Header("Content-Type", "application/pdf")
+    var pagination dto.PaginationRequest
+    if err := c.ShouldBindQuery(&pagination); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
 
-    // Serve the file
-    c.File(filePath)
-}
\ No newline at end of file
+    page := 1
+    pageSize := 20
+    if pagination.Page > 0 {
+        page = pagination.Page
+    }
+    if pagination.PageSize > 0 {
+        pageSize = pagination.PageSize
+    }
+
+    ctx, cancel := middleware.QueryContext(c)
+    defer cancel()
+
+    downloads, total, err := h.bookService.GetBookDownloads(ctx, bookID, userID, page, pageSize)
+    if err != nil {
+        utils.HandleError(c, err)
+        return
+    }
+
+    response := dto.DownloadListResponse{
+        Downloads:  utils.MapDownloadsToResponse(downloads),
+        Pagination: utils.BuildPaginationResponse(page, pageSize, total),
+    }
+
+    c.JSON(http.StatusOK, response)
+}
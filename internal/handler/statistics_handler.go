@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"library-project/internal/dto"
+	"library-project/internal/middleware"
+	"library-project/internal/service"
+	"library-project/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatisticsHandler struct {
+	statsService *service.StatisticsService
+}
+
+func NewStatisticsHandler(statsService *service.StatisticsService) *StatisticsHandler {
+	return &StatisticsHandler{statsService: statsService}
+}
+
+// GetDashboard godoc
+// @Summary Get library dashboard (Owner only)
+// @Description Get library totals together with the most popular and most recent books (Owner only)
+// @Tags statistics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.DashboardResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dashboard [get]
+func (h *StatisticsHandler) GetDashboard(c *gin.Context) {
+	ctx, cancel := middleware.QueryContext(c)
+	defer cancel()
+
+	dashboard, err := h.statsService.GetDashboard(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetBookStatistics godoc
+// @Summary Get book statistics (Owner only)
+// @Description Get totals and a daily or weekly activity timeline for a book (Owner of the book only)
+// @Tags statistics
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param interval query string false "Timeline bucket size (default: day)" Enums(day, week)
+// @Param days query int false "Number of days covered by the timeline (default: 30, max: 365)"
+// @Success 200 {object} dto.BookStatisticsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/statistics [get]
+func (h *StatisticsHandler) GetBookStatistics(c *gin.Context) {
+	bookID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req dto.StatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := middleware.QueryContext(c)
+	defer cancel()
+
+	stats, err := h.statsService.GetBookStatistics(ctx, bookID, userID, &req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
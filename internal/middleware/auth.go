@@ -21,7 +21,7 @@ func drainBody(c *gin.Context) {
     }
 }
 
-func AuthMiddleware(cfg *config.Config, userRepo ...*repository.UserRepository) gin.HandlerFunc {
+func AuthMiddleware(cfg *config.Config, userRepo ...repository.UserRepository) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
@@ -49,8 +49,16 @@ func AuthMiddleware(cfg *config.Config, userRepo ...*repository.UserRepository)
 
         // Check if token was invalidated (logout/refresh)
         if len(userRepo) > 0 && userRepo[0] != nil {
-            user, err := userRepo[0].FindByID(claims.UserID)
-            if err != nil || user == nil {
+            ctx, cancel := QueryContext(c)
+            user, err := userRepo[0].FindByID(ctx, claims.UserID)
+            cancel()
+            if err != nil {
+                drainBody(c)
+                utils.HandleError(c, err)
+                c.Abort()
+                return
+            }
+            if user == nil {
                 drainBody(c)
                 c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
                 c.Abort()
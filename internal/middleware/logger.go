@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"library-project/internal/utils"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -37,8 +40,13 @@ func LoggerMiddleware() gin.HandlerFunc {
 			fields["user_id"] = userID
 		}
 
-		// Log based on status code
-		if statusCode >= 500 {
+		// Requests cut short by the client or by the query timeout are logged
+		// on their own so they do not read as server errors
+		if errors.Is(c.Request.Context().Err(), context.Canceled) {
+			utils.LogInfo("HTTP request canceled by client", fields)
+		} else if statusCode == http.StatusGatewayTimeout {
+			utils.LogWarning("HTTP request timed out", fields)
+		} else if statusCode >= 500 {
 			utils.LogError(nil, "HTTP request completed with server error", fields)
 		} else if statusCode >= 400 {
 			utils.LogWarning("HTTP request completed with client error", fields)
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const queryTimeoutKey = "query_timeout"
+
+// TimeoutMiddleware makes the query timeout available to QueryContext. The
+// deadline is not put on the request itself: the time a client spends
+// sending the body, and the transfer of files to and from storage, must not
+// count against the database work of the request.
+// A timeout of zero leaves the database work without a deadline.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(queryTimeoutKey, timeout)
+		c.Next()
+	}
+}
+
+// QueryContext returns the request context with the query timeout starting
+// now, for the database work of a handler. The work is still cancelled when
+// the client disconnects. Callers must call cancel once the work is done.
+func QueryContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	timeout := c.GetDuration(queryTimeoutKey)
+	if timeout <= 0 {
+		return context.WithCancel(c.Request.Context())
+	}
+	return context.WithTimeout(c.Request.Context(), timeout)
+}
@@ -0,0 +1,464 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeDriver serves the statements the migrator sends to PostgreSQL from
+// memory. Each data source name is a separate database.
+type fakeDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+var (
+	drv      = &fakeDriver{dbs: make(map[string]*fakeDB)}
+	fakeDBID atomic.Int64
+)
+
+func init() {
+	sql.Register("migratetest", drv)
+}
+
+type fakeDB struct {
+	// lock is the advisory lock, full while a connection holds it
+	lock chan struct{}
+
+	mu       sync.Mutex
+	holder   *fakeConn
+	lockKeys []int64
+	records  map[int]fakeRecord
+	// executed lists the committed migration scripts in order
+	executed []string
+}
+
+type fakeRecord struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &fakeDB{lock: make(chan struct{}, 1), records: make(map[int]fakeRecord)}
+		d.dbs[name] = db
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeTx{conn: c}
+	return c.tx, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = strings.TrimSpace(query)
+
+	switch {
+	case strings.HasPrefix(query, "SELECT pg_advisory_lock("):
+		select {
+		case c.db.lock <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.db.mu.Lock()
+		c.db.holder = c
+		c.db.lockKeys = append(c.db.lockKeys, args[0].Value.(int64))
+		c.db.mu.Unlock()
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(query, "SELECT pg_advisory_unlock("):
+		c.db.mu.Lock()
+		defer c.db.mu.Unlock()
+		if c.db.holder == c {
+			c.db.holder = nil
+			<-c.db.lock
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+		return driver.RowsAffected(0), nil
+	}
+
+	if err := c.checkLock(); err != nil {
+		return nil, err
+	}
+	if c.tx == nil {
+		return nil, fmt.Errorf("statement outside of a transaction: %s", query)
+	}
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		version, checksum := int(args[0].Value.(int64)), args[2].Value.(string)
+		c.tx.ops = append(c.tx.ops, func(db *fakeDB) {
+			db.records[version] = fakeRecord{checksum: checksum, appliedAt: time.Now()}
+		})
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		version := int(args[0].Value.(int64))
+		c.tx.ops = append(c.tx.ops, func(db *fakeDB) {
+			delete(db.records, version)
+		})
+	case strings.Contains(query, "FAIL"):
+		return nil, errors.New("syntax error")
+	default:
+		c.tx.ops = append(c.tx.ops, func(db *fakeDB) {
+			db.executed = append(db.executed, query)
+		})
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(strings.TrimSpace(query), "SELECT version, checksum, applied_at FROM schema_migrations") {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	if err := c.checkLock(); err != nil {
+		return nil, err
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	rows := &fakeRows{}
+	for version, record := range c.db.records {
+		rows.values = append(rows.values, []driver.Value{int64(version), record.checksum, record.appliedAt})
+	}
+	sort.Slice(rows.values, func(i, j int) bool {
+		return rows.values[i][0].(int64) < rows.values[j][0].(int64)
+	})
+	return rows, nil
+}
+
+// checkLock fails statements sent without holding the migration lock
+func (c *fakeConn) checkLock() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if c.db.holder != c {
+		return errors.New("migration lock not held")
+	}
+	return nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+	ops  []func(db *fakeDB)
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.conn.db.mu.Lock()
+	defer tx.conn.db.mu.Unlock()
+
+	for _, op := range tx.ops {
+		op(tx.conn.db)
+	}
+	tx.conn.tx = nil
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"version", "checksum", "applied_at"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// openFakeDB returns a fresh fake database for the test
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+
+	name := fmt.Sprintf("%s-%d", t.Name(), fakeDBID.Add(1))
+	db, err := sql.Open("migratetest", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	drv.mu.Lock()
+	defer drv.mu.Unlock()
+	return db, drv.dbs[name]
+}
+
+func (db *fakeDB) executedScripts() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return slices.Clone(db.executed)
+}
+
+// fixtures has versions whose names sort differently from their numbers
+func fixtures() fstest.MapFS {
+	return fstest.MapFS{
+		"1_users.up.sql":       {Data: []byte("CREATE users")},
+		"1_users.down.sql":     {Data: []byte("DROP users")},
+		"2_posts.up.sql":       {Data: []byte("CREATE posts")},
+		"2_posts.down.sql":     {Data: []byte("DROP posts")},
+		"10_comments.up.sql":   {Data: []byte("CREATE comments")},
+		"10_comments.down.sql": {Data: []byte("DROP comments")},
+		"README.md":            {Data: []byte("not a migration")},
+	}
+}
+
+func newMigrator(t *testing.T, db *sql.DB, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+
+	migrator, err := New(db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func versions(migrations []*Migration) []int {
+	var result []int
+	for _, m := range migrations {
+		result = append(result, m.Version)
+	}
+	return result
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions(migrations), []int{1, 2, 10}) {
+		t.Fatalf("expected migrations in numeric order, got %v", versions(migrations))
+	}
+	if migrations[2].Name != "comments" || migrations[2].Down != "DROP comments" || len(migrations[2].Checksum) != 64 {
+		t.Errorf("unexpected migration %+v", migrations[2])
+	}
+
+	missingUp := fixtures()
+	delete(missingUp, "2_posts.up.sql")
+	if _, err := load(missingUp); err == nil || !strings.Contains(err.Error(), "no up script") {
+		t.Errorf("expected a missing up script to be reported, got %v", err)
+	}
+
+	conflicting := fixtures()
+	conflicting["2_articles.down.sql"] = &fstest.MapFile{Data: []byte("DROP articles")}
+	if _, err := load(conflicting); err == nil || !strings.Contains(err.Error(), "conflicting names") {
+		t.Errorf("expected conflicting names to be reported, got %v", err)
+	}
+}
+
+func TestUpAppliesPendingMigrationsInOrder(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fsys := fixtures()
+	delete(fsys, "10_comments.up.sql")
+	delete(fsys, "10_comments.down.sql")
+
+	applied, err := newMigrator(t, db, fsys).Up(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions(applied), []int{1, 2}) {
+		t.Fatalf("expected versions 1 and 2 to be applied, got %v", versions(applied))
+	}
+
+	// Only the new migration is applied on the next run
+	applied, err = newMigrator(t, db, fixtures()).Up(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions(applied), []int{10}) {
+		t.Fatalf("expected only version 10 to be applied, got %v", versions(applied))
+	}
+
+	want := []string{"CREATE users", "CREATE posts", "CREATE comments"}
+	if got := fake.executedScripts(); !slices.Equal(got, want) {
+		t.Errorf("expected scripts %v, got %v", want, got)
+	}
+}
+
+func TestUpRejectsModifiedMigrations(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fsys := fixtures()
+	delete(fsys, "10_comments.up.sql")
+	delete(fsys, "10_comments.down.sql")
+	if _, err := newMigrator(t, db, fsys).Up(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	modified := fixtures()
+	modified["2_posts.up.sql"] = &fstest.MapFile{Data: []byte("CREATE posts WITH title")}
+	applied, err := newMigrator(t, db, modified).Up(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "2_posts was modified after being applied") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if len(applied) != 0 || slices.Contains(fake.executedScripts(), "CREATE comments") {
+		t.Errorf("expected no migration after the mismatch to be applied, got %v", fake.executedScripts())
+	}
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	db, _ := openFakeDB(t)
+	fsys := fixtures()
+	fsys["2_posts.up.sql"] = &fstest.MapFile{Data: []byte("FAIL")}
+	migrator := newMigrator(t, db, fsys)
+
+	applied, err := migrator.Up(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "applying migration 2_posts") {
+		t.Fatalf("expected migration 2 to fail, got %v", err)
+	}
+	if !slices.Equal(versions(applied), []int{1}) {
+		t.Errorf("expected only version 1 to be applied, got %v", versions(applied))
+	}
+
+	statuses, err := migrator.Status(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.Applied != (status.Version == 1) {
+			t.Errorf("unexpected status %+v", status)
+		}
+	}
+}
+
+func TestDownRollsBackTheLatestMigrations(t *testing.T) {
+	db, fake := openFakeDB(t)
+	migrator := newMigrator(t, db, fixtures())
+	if _, err := migrator.Up(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	rolledBack, err := migrator.Down(t.Context(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions(rolledBack), []int{10, 2}) {
+		t.Fatalf("expected versions 10 and 2 to be rolled back, got %v", versions(rolledBack))
+	}
+
+	statuses, err := migrator.Status(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 || !statuses[0].Applied || statuses[0].AppliedAt == nil ||
+		statuses[1].Applied || statuses[2].Applied || statuses[2].AppliedAt != nil {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+
+	// Asking for more steps than applied rolls back what is left
+	rolledBack, err = migrator.Down(t.Context(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions(rolledBack), []int{1}) {
+		t.Fatalf("expected version 1 to be rolled back, got %v", versions(rolledBack))
+	}
+
+	want := []string{"CREATE users", "CREATE posts", "CREATE comments", "DROP comments", "DROP posts", "DROP users"}
+	if got := fake.executedScripts(); !slices.Equal(got, want) {
+		t.Errorf("expected scripts %v, got %v", want, got)
+	}
+}
+
+func TestDownRequiresADownScript(t *testing.T) {
+	db, _ := openFakeDB(t)
+	fsys := fixtures()
+	delete(fsys, "10_comments.down.sql")
+	migrator := newMigrator(t, db, fsys)
+	if _, err := migrator.Up(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	rolledBack, err := migrator.Down(t.Context(), 1)
+	if err == nil || !strings.Contains(err.Error(), "10_comments has no down script") {
+		t.Fatalf("expected a missing down script to be reported, got %v", err)
+	}
+	if len(rolledBack) != 0 {
+		t.Errorf("expected nothing to be rolled back, got %v", versions(rolledBack))
+	}
+}
+
+func TestMigrationsHoldTheLock(t *testing.T) {
+	db, fake := openFakeDB(t)
+
+	// The fake rejects any statement sent without the lock, so concurrent
+	// runs only pass if they take turns
+	var wg sync.WaitGroup
+	results := make([][]*Migration, 4)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = newMigrator(t, db, fixtures()).Up(t.Context())
+		}()
+	}
+	wg.Wait()
+
+	applied := 0
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+		applied += len(results[i])
+	}
+	if applied != 3 || len(fake.executedScripts()) != 3 {
+		t.Errorf("expected every migration to be applied once, got %d runs of %v", applied, fake.executedScripts())
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.holder != nil || len(fake.lock) != 0 {
+		t.Error("expected the lock to be released")
+	}
+	for _, key := range fake.lockKeys {
+		if key != lockKey {
+			t.Errorf("expected lock key %d, got %d", lockKey, key)
+		}
+	}
+}
@@ -25,27 +25,91 @@ type Category struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	ParentID    *string   `json:"parent_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+type CategoryWithBookCount struct {
+	Category
+	BookCount int `json:"book_count"`
+}
+
 type Book struct {
+	ID                string     `json:"id"`
+	Title             string     `json:"title"`
+	Description       string     `json:"description"`
+	PDFFile           string     `json:"pdf_file"`
+	FileHash          string     `json:"-"`
+	Format            string     `json:"format"`
+	CoverImage        string     `json:"-"` // storage key prefix of the thumbnails, empty without a cover
+	CategoryID        string     `json:"category_id"`
+	OwnerID           string     `json:"owner_id"`
+	Author            string     `json:"author"`
+	Subject           string     `json:"subject"`
+	Keywords          string     `json:"keywords"`
+	PageCount         int        `json:"page_count"` // 0 when unknown
+	DocumentCreatedAt *time.Time `json:"document_created_at,omitempty"`
+	LikeCount         int        `json:"like_count"`
+	DislikeCount      int        `json:"dislike_count"`
+	SaveCount         int        `json:"save_count"`
+	DownloadCount     int        `json:"download_count"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// BookFile is one version of the file of a book
+type BookFile struct {
 	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	PDFFile      string    `json:"pdf_file"`
-	CategoryID   string    `json:"category_id"`
-	OwnerID      string    `json:"owner_id"`
-	LikeCount    int       `json:"like_count"`
-	DislikeCount int       `json:"dislike_count"`
-	SaveCount    int       `json:"save_count"`
+	BookID       string    `json:"book_id"`
+	Version      int       `json:"version"`
+	PDFFile      string    `json:"-"`
+	FileHash     string    `json:"-"`
+	Format       string    `json:"format"`
+	PageCount    int       `json:"page_count"`
+	UploadedBy   string    `json:"uploaded_by,omitempty"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type BookWithCategory struct {
 	Book
-	CategoryName string `json:"category_name"`
+	CategoryName string       `json:"category_name"`
+	Authors      []BookAuthor `json:"authors"`
+	Tags         []string     `json:"tags"` // sorted by name
+}
+
+// Author is a writer of books, unlike the owner who uploaded them
+type Author struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AuthorWithBookCount struct {
+	Author
+	BookCount int `json:"book_count"`
+}
+
+// BookAuthor names one of the authors of a book
+type BookAuthor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Tag is a free-form label of books
+type Tag struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagWithBookCount struct {
+	Tag
+	BookCount int `json:"book_count"`
 }
 
 type SavedBook struct {
@@ -56,21 +120,46 @@ type SavedBook struct {
 }
 
 type Comment struct {
-	ID        string    `json:"id"`
-	BookID    string    `json:"book_id"`
-	UserID    string    `json:"user_id"`
-	Content   string    `json:"content"`
-	ParentID  *string   `json:"parent_id,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	BookID       string     `json:"book_id"`
+	UserID       string     `json:"user_id"`
+	Content      string     `json:"content"`
+	ParentID     *string    `json:"parent_id,omitempty"`
+	Depth        int        `json:"depth"`
+	LikeCount    int        `json:"like_count"`
+	DislikeCount int        `json:"dislike_count"`
+	DeletedAt    *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
+// DeletedCommentContent replaces the content of deleted comments that still have replies
+const DeletedCommentContent = "[deleted]"
+
 type CommentWithUser struct {
 	Comment
 	UserFirstName string `json:"user_first_name"`
 	UserLastName  string `json:"user_last_name"`
 }
 
+// BookView records a book being opened in the web reader
+type BookView struct {
+	ID        string    `json:"id"`
+	BookID    string    `json:"book_id"`
+	UserID    string    `json:"user_id"`
+	ViewedAt  time.Time `json:"viewed_at"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+// ReadingProgress is the last page a user read of a book
+type ReadingProgress struct {
+	UserID    string    `json:"user_id"`
+	BookID    string    `json:"book_id"`
+	Page      int       `json:"page"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Like struct {
 	ID        string    `json:"id"`
 	BookID    string    `json:"book_id"`
@@ -79,6 +168,14 @@ type Like struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type CommentLike struct {
+	ID        string    `json:"id"`
+	CommentID string    `json:"comment_id"`
+	UserID    string    `json:"user_id"`
+	IsLike    bool      `json:"is_like"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type RefreshToken struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
@@ -94,4 +191,29 @@ type Download struct {
 	DownloadedAt time.Time `json:"downloaded_at"`
 	IPAddress    string    `json:"ip_address,omitempty"`
 	UserAgent    string    `json:"user_agent,omitempty"`
-}
\ No newline at end of file
+}
+
+type DownloadWithUser struct {
+	Download
+	UserEmail     string `json:"user_email"`
+	UserFirstName string `json:"user_first_name"`
+	UserLastName  string `json:"user_last_name"`
+}
+
+type LibraryTotals struct {
+	Books      int `json:"books"`
+	Categories int `json:"categories"`
+	Saves      int `json:"saves"`
+	Comments   int `json:"comments"`
+}
+
+// ActivityBucket aggregates the activity on a book within one time period
+type ActivityBucket struct {
+	Period    time.Time `json:"period"`
+	Views     int       `json:"views"`
+	Downloads int       `json:"downloads"`
+	Saves     int       `json:"saves"`
+	Likes     int       `json:"likes"`
+	Dislikes  int       `json:"dislikes"`
+	Comments  int       `json:"comments"`
+}
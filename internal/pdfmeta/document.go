@@ -0,0 +1,640 @@
+package pdfmeta
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// maxObjectNumber bounds object numbers; real files stay far below it
+const maxObjectNumber = 1 << 23
+
+// maxStreamSize bounds how much a single stream may inflate to, and how
+// much of the file a single stream may be read from
+const maxStreamSize = 64 << 20
+
+// maxObjectSize bounds how much of the file is read to parse a single
+// object or cross-reference table
+const maxObjectSize = 16 << 20
+
+// maxScanSize bounds how much of a file with a broken cross-reference table
+// is scanned for objects
+const maxScanSize = 64 << 20
+
+// initialWindow is how much of the file is read first to parse an object
+const initialWindow = 4 << 10
+
+// xrefEntry locates an object, either at an offset in the file or inside
+// an object stream
+type xrefEntry struct {
+	offset   int
+	inStream bool
+	stream   int // the object stream's number
+	index    int // the object's index inside the object stream
+}
+
+// document gives access to the objects of a PDF file. Only the parts of the
+// file the objects are parsed from are read.
+type document struct {
+	r       io.ReaderAt
+	size    int64
+	xref    map[int]xrefEntry
+	trailer dict
+
+	cache         map[int]object
+	loading       map[int]bool
+	objectStreams map[int]*objectStreamData
+}
+
+func newDocument(r io.ReaderAt, size int64) (*document, error) {
+	d := &document{
+		r:             r,
+		size:          size,
+		xref:          make(map[int]xrefEntry),
+		cache:         make(map[int]object),
+		loading:       make(map[int]bool),
+		objectStreams: make(map[int]*objectStreamData),
+	}
+	if err := d.readXref(); err != nil || d.trailer["Root"] == nil {
+		// Many files in the wild have broken cross-reference tables;
+		// readers recover by scanning the file for objects
+		d.xref = make(map[int]xrefEntry)
+		d.trailer = nil
+		clear(d.cache)
+		clear(d.objectStreams)
+		if err := d.reconstructXref(); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// readAt reads up to n bytes of the file at offset; fewer are returned only
+// at the end of the file
+func (d *document) readAt(offset int64, n int) ([]byte, error) {
+	if offset < 0 || offset >= d.size {
+		return nil, nil
+	}
+	buf := make([]byte, min(int64(n), d.size-offset))
+	read, err := d.r.ReadAt(buf, offset)
+	if read == len(buf) {
+		err = nil
+	}
+	return buf[:read], err
+}
+
+// parseAt runs parse on the file from offset on. How far an object extends
+// is not known up front, so parse first sees a small window of the file,
+// which is doubled up to limit as long as parse fails or reaches its end.
+func (d *document) parseAt(offset int64, limit int, parse func(p *parser) error) error {
+	for window := initialWindow; ; window *= 2 {
+		data, err := d.readAt(offset, min(window, limit))
+		if err != nil {
+			return err
+		}
+		p := &parser{data: data}
+		err = parse(p)
+		if (err == nil && p.pos < len(data)) || len(data) < min(window, limit) {
+			return err
+		}
+		if window >= limit {
+			if err == nil {
+				err = fmt.Errorf("object at offset %d too large", offset)
+			}
+			return err
+		}
+	}
+}
+
+// readXref follows the chain of cross-reference sections from the last
+// startxref to the oldest revision. Newer entries win over older ones.
+func (d *document) readXref() error {
+	tail, err := d.readAt(max(d.size-1024, 0), 1024)
+	if err != nil {
+		return err
+	}
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i < 0 {
+		return errors.New("missing startxref")
+	}
+	p := &parser{data: tail, pos: i + len("startxref")}
+	offset, ok := p.integer()
+	if !ok {
+		return errors.New("malformed startxref")
+	}
+
+	seen := make(map[int]bool)
+	for !seen[offset] {
+		seen[offset] = true
+		trailer, err := d.readXrefSection(offset)
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		// Hybrid files list the objects in object streams separately
+		if stm, ok := trailer["XRefStm"].(int64); ok && !seen[int(stm)] {
+			seen[int(stm)] = true
+			if _, err := d.readXrefSection(int(stm)); err != nil {
+				return err
+			}
+		}
+		prev, ok := trailer["Prev"].(int64)
+		if !ok {
+			break
+		}
+		offset = int(prev)
+	}
+	return nil
+}
+
+// readXrefSection reads a cross-reference table or stream and returns its
+// trailer dictionary
+func (d *document) readXrefSection(offset int) (dict, error) {
+	if offset < 0 || int64(offset) >= d.size {
+		return nil, fmt.Errorf("cross-reference section offset %d out of range", offset)
+	}
+
+	var trailer dict
+	isTable := true
+	err := d.parseAt(int64(offset), maxObjectSize, func(p *parser) error {
+		p.skipSpace()
+		if !p.hasPrefix("xref") {
+			isTable = false
+			return nil
+		}
+		p.pos += len("xref")
+
+		for {
+			p.skipSpace()
+			if p.hasPrefix("trailer") {
+				p.pos += len("trailer")
+				obj, err := p.object(0)
+				if err != nil {
+					return err
+				}
+				var ok bool
+				if trailer, ok = obj.(dict); !ok {
+					return errors.New("malformed trailer")
+				}
+				return nil
+			}
+
+			first, ok1 := p.integer()
+			count, ok2 := p.integer()
+			if !ok1 || !ok2 {
+				return errors.New("malformed cross-reference table")
+			}
+			for i := 0; i < count; i++ {
+				offset, ok1 := p.integer()
+				_, ok2 := p.integer()
+				kind := p.keyword()
+				if !ok1 || !ok2 || (kind != "n" && kind != "f") {
+					return errors.New("malformed cross-reference entry")
+				}
+				if kind == "n" {
+					d.addEntry(first+i, xrefEntry{offset: offset})
+				}
+			}
+		}
+	})
+	if !isTable {
+		return d.readXrefStream(offset)
+	}
+	return trailer, err
+}
+
+// readXrefStream reads a PDF 1.5 cross-reference stream, whose dictionary
+// doubles as the trailer
+func (d *document) readXrefStream(offset int) (dict, error) {
+	obj, err := d.parseObjectAt(offset, -1)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*stream)
+	if !ok || s.dict["Type"] != name("XRef") {
+		return nil, errors.New("missing cross-reference section")
+	}
+	data, err := d.decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var widths [3]int
+	w, _ := s.dict["W"].(array)
+	if len(w) != 3 {
+		return nil, errors.New("malformed cross-reference stream widths")
+	}
+	rowSize := 0
+	for i := range widths {
+		n, ok := w[i].(int64)
+		if !ok || n < 0 || n > 8 {
+			return nil, errors.New("malformed cross-reference stream widths")
+		}
+		widths[i] = int(n)
+		rowSize += int(n)
+	}
+	if rowSize == 0 {
+		return nil, errors.New("malformed cross-reference stream widths")
+	}
+
+	index, _ := s.dict["Index"].(array)
+	if index == nil {
+		size, _ := s.dict["Size"].(int64)
+		index = array{int64(0), size}
+	}
+	for i := 0; i+1 < len(index); i += 2 {
+		first, _ := index[i].(int64)
+		count, _ := index[i+1].(int64)
+		for j := 0; j < int(count) && len(data) >= rowSize; j++ {
+			var fields [3]int
+			for k, width := range widths {
+				for _, b := range data[:width] {
+					fields[k] = fields[k]<<8 | int(b)
+				}
+				data = data[width:]
+			}
+			if widths[0] == 0 {
+				fields[0] = 1 // the type defaults to an object at an offset
+			}
+			switch fields[0] {
+			case 1:
+				d.addEntry(int(first)+j, xrefEntry{offset: fields[1]})
+			case 2:
+				d.addEntry(int(first)+j, xrefEntry{inStream: true, stream: fields[1], index: fields[2]})
+			}
+		}
+	}
+	return s.dict, nil
+}
+
+func (d *document) addEntry(num int, entry xrefEntry) {
+	if num < 0 || num > maxObjectNumber {
+		return
+	}
+	if _, exists := d.xref[num]; !exists {
+		d.xref[num] = entry
+	}
+}
+
+var objectHeader = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj\b`)
+
+// reconstructXref finds the objects by scanning the file, up to
+// maxScanSize. Later definitions of an object win, as they come from later
+// revisions.
+func (d *document) reconstructXref() error {
+	data, err := d.readAt(0, maxScanSize)
+	if err != nil {
+		return err
+	}
+	for _, m := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		p := &parser{data: data, pos: m[2]}
+		if num, ok := p.integer(); ok && num <= maxObjectNumber {
+			d.xref[num] = xrefEntry{offset: m[0]}
+		}
+	}
+
+	// The last trailer names the catalog; files with cross-reference
+	// streams have no trailer keyword and need their objects searched
+	for i := bytes.LastIndex(data, []byte("trailer")); i >= 0 && d.trailer == nil; i = bytes.LastIndex(data[:i], []byte("trailer")) {
+		p := &parser{data: data, pos: i + len("trailer")}
+		if t, err := p.object(0); err == nil {
+			if t, ok := t.(dict); ok && t["Root"] != nil {
+				d.trailer = t
+			}
+		}
+	}
+
+	var objectStreams []int
+	var xrefStream dict
+	latest := -1
+	for num, entry := range d.xref {
+		obj, _ := d.parseObjectAt(entry.offset, num)
+		d.cache[num] = obj
+		s, ok := obj.(*stream)
+		if !ok {
+			continue
+		}
+		switch s.dict["Type"] {
+		case name("ObjStm"):
+			objectStreams = append(objectStreams, num)
+		case name("XRef"):
+			if s.dict["Root"] != nil && entry.offset > latest {
+				xrefStream, latest = s.dict, entry.offset
+			}
+		}
+	}
+	if d.trailer == nil {
+		d.trailer = xrefStream
+	}
+	for _, num := range objectStreams {
+		if objs, err := d.objectStream(num); err == nil {
+			for i, member := range objs.numbers {
+				d.addEntry(member, xrefEntry{inStream: true, stream: num, index: i})
+			}
+		}
+	}
+
+	if d.trailer == nil {
+		for num := range d.xref {
+			if obj, ok := d.load(num).(dict); ok && obj["Type"] == name("Catalog") {
+				d.trailer = dict{"Root": ref{num: num}}
+				break
+			}
+		}
+	}
+	if d.trailer == nil {
+		return errors.New("no document catalog found")
+	}
+	return nil
+}
+
+// resolve follows references until it reaches a direct object. Objects
+// that cannot be read resolve to nil.
+func (d *document) resolve(obj object) object {
+	for i := 0; i < 16; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.load(r.num)
+	}
+	return nil
+}
+
+func (d *document) load(num int) object {
+	if obj, ok := d.cache[num]; ok {
+		return obj
+	}
+	entry, ok := d.xref[num]
+	if !ok || d.loading[num] {
+		return nil
+	}
+	d.loading[num] = true
+	defer delete(d.loading, num)
+
+	var obj object
+	if entry.inStream {
+		if objs, err := d.objectStream(entry.stream); err == nil && entry.index < len(objs.numbers) {
+			p := &parser{data: objs.data, pos: objs.offsets[entry.index]}
+			obj, _ = p.object(0)
+		}
+	} else {
+		obj, _ = d.parseObjectAt(entry.offset, num)
+	}
+	d.cache[num] = obj
+	return obj
+}
+
+// parseObjectAt reads the indirect object "num gen obj ... endobj" at
+// offset. A negative num accepts any object number.
+func (d *document) parseObjectAt(offset, num int) (object, error) {
+	if offset < 0 || int64(offset) >= d.size {
+		return nil, fmt.Errorf("object offset %d out of range", offset)
+	}
+
+	var obj object
+	dataStart := int64(-1)
+	err := d.parseAt(int64(offset), maxObjectSize, func(p *parser) error {
+		n, ok1 := p.integer()
+		_, ok2 := p.integer()
+		if !ok1 || !ok2 || p.keyword() != "obj" || (num >= 0 && n != num) {
+			return fmt.Errorf("no object %d at offset %d", num, offset)
+		}
+		var err error
+		if obj, err = p.object(0); err != nil {
+			return err
+		}
+		if _, ok := obj.(dict); !ok {
+			return nil
+		}
+		if save := p.pos; p.keyword() != "stream" {
+			p.pos = save
+			return nil
+		}
+		if p.hasPrefix("\r\n") {
+			p.pos += 2
+		} else if p.hasPrefix("\n") || p.hasPrefix("\r") {
+			p.pos++
+		}
+		dataStart = int64(offset + p.pos)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dataStart < 0 {
+		return obj, nil
+	}
+	s, err := d.streamData(dataStart, obj.(dict))
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// streamData reads the data of a stream starting at offset. A wrong Length
+// is common enough to fall back to looking for endstream.
+func (d *document) streamData(offset int64, header dict) (*stream, error) {
+	if length, ok := d.resolve(header["Length"]).(int64); ok && length >= 0 && length <= maxStreamSize && offset+length <= d.size {
+		data, err := d.readAt(offset, int(length)+32)
+		if err != nil {
+			return nil, err
+		}
+		p := &parser{data: data, pos: int(length)}
+		if p.keyword() == "endstream" {
+			return &stream{dict: header, data: data[:length]}, nil
+		}
+	}
+
+	var data []byte
+	err := d.parseAt(offset, maxStreamSize, func(p *parser) error {
+		end := bytes.Index(p.data, []byte("endstream"))
+		if end < 0 {
+			return errors.New("unterminated stream")
+		}
+		data, p.pos = p.data[:end], end
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return &stream{dict: header, data: data}, nil
+}
+
+// decode applies the stream's filters. Only the filters metadata and
+// cross-reference streams use in practice are supported.
+func (d *document) decode(s *stream) ([]byte, error) {
+	filters := d.resolve(s.dict["Filter"])
+	params := d.resolve(s.dict["DecodeParms"])
+	if f, ok := filters.(name); ok {
+		filters, params = array{f}, array{params}
+	}
+	filterList, _ := filters.(array)
+	paramList, _ := params.(array)
+
+	data := s.data
+	for i, filter := range filterList {
+		var p dict
+		if i < len(paramList) {
+			p, _ = d.resolve(paramList[i]).(dict)
+		}
+		switch d.resolve(filter) {
+		case name("FlateDecode"), name("Fl"):
+			var err error
+			if data, err = inflate(data); err != nil {
+				return nil, err
+			}
+			if data, err = unpredict(data, p); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+	}
+	return data, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, maxStreamSize+1))
+	if len(out) > maxStreamSize {
+		return nil, errors.New("stream too large")
+	}
+	// Truncated streams are common; keep what could be inflated
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+// unpredict reverses the PNG predictors cross-reference streams are
+// usually encoded with
+func unpredict(data []byte, params dict) ([]byte, error) {
+	predictor, _ := params["Predictor"].(int64)
+	if predictor <= 1 {
+		return data, nil
+	}
+	if predictor < 10 {
+		return nil, fmt.Errorf("unsupported predictor %d", predictor)
+	}
+
+	colors, bits, columns := int64(1), int64(8), int64(1)
+	if v, ok := params["Colors"].(int64); ok {
+		colors = v
+	}
+	if v, ok := params["BitsPerComponent"].(int64); ok {
+		bits = v
+	}
+	if v, ok := params["Columns"].(int64); ok {
+		columns = v
+	}
+	if colors < 1 || colors > 32 || bits < 1 || bits > 16 || columns < 1 || columns > 1<<20 {
+		return nil, errors.New("malformed predictor parameters")
+	}
+	bpp := int(max((colors*bits+7)/8, 1))
+	rowSize := int((colors*bits*columns + 7) / 8)
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowSize)
+	for len(data) > rowSize {
+		filter, row := data[0], data[1:rowSize+1]
+		data = data[rowSize+1:]
+		cur := make([]byte, rowSize)
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = cur[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			switch filter {
+			case 0:
+				cur[i] = row[i]
+			case 1:
+				cur[i] = row[i] + left
+			case 2:
+				cur[i] = row[i] + up
+			case 3:
+				cur[i] = row[i] + byte((int(left)+int(up))/2)
+			case 4:
+				cur[i] = row[i] + paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("unknown PNG filter %d", filter)
+			}
+		}
+		out = append(out, cur...)
+		prev = cur
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// objectStreamData is a decoded object stream: the numbers of the objects
+// it holds and where each starts in data
+type objectStreamData struct {
+	data    []byte
+	numbers []int
+	offsets []int
+}
+
+func (d *document) objectStream(num int) (*objectStreamData, error) {
+	if objs, ok := d.objectStreams[num]; ok {
+		return objs, nil
+	}
+	s, ok := d.load(num).(*stream)
+	if !ok || s.dict["Type"] != name("ObjStm") {
+		return nil, fmt.Errorf("object %d is not an object stream", num)
+	}
+	data, err := d.decode(s)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := d.resolve(s.dict["N"]).(int64)
+	first, _ := d.resolve(s.dict["First"]).(int64)
+	if n < 0 || first < 0 || first > int64(len(data)) {
+		return nil, errors.New("malformed object stream")
+	}
+
+	objs := &objectStreamData{data: data}
+	p := &parser{data: data[:first]}
+	for i := int64(0); i < n; i++ {
+		member, ok1 := p.integer()
+		offset, ok2 := p.integer()
+		if !ok1 || !ok2 || first+int64(offset) > int64(len(data)) {
+			return nil, errors.New("malformed object stream header")
+		}
+		objs.numbers = append(objs.numbers, member)
+		objs.offsets = append(objs.offsets, int(first)+offset)
+	}
+	d.objectStreams[num] = objs
+	return objs, nil
+}
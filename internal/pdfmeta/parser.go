@@ -0,0 +1,309 @@
+package pdfmeta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// The PDF objects the parser produces. Numbers are int64 or float64,
+// booleans bool, strings string and null nil.
+type (
+	object interface{}
+	name   string
+	dict   map[name]object
+	array  []object
+	ref    struct{ num, gen int }
+	stream struct {
+		dict dict
+		data []byte // still encoded with the stream's filters
+	}
+)
+
+// maxNesting bounds how deep arrays and dictionaries may nest, so a
+// malicious file cannot exhaust the stack
+const maxNesting = 64
+
+var errNesting = errors.New("objects nested too deep")
+
+// parser reads PDF syntax from data starting at pos
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips white space and comments
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case isSpace(c):
+			p.pos++
+		case c == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return bytes.HasPrefix(p.data[p.pos:], []byte(s))
+}
+
+// keyword reads the next run of regular characters, e.g. a number, obj or R
+func (p *parser) keyword() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// integer reads a non-negative integer such as an object number
+func (p *parser) integer() (int, bool) {
+	n, err := strconv.Atoi(p.keyword())
+	return n, err == nil && n >= 0
+}
+
+// object reads the next direct object. References are returned as ref and
+// left to the caller to resolve.
+func (p *parser) object(depth int) (object, error) {
+	if depth > maxNesting {
+		return nil, errNesting
+	}
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch c := p.data[p.pos]; c {
+	case '/':
+		return p.name(), nil
+	case '(':
+		return p.literalString()
+	case '<':
+		if p.hasPrefix("<<") {
+			p.pos += 2
+			return p.dict(depth)
+		}
+		return p.hexString()
+	case '[':
+		p.pos++
+		return p.array(depth)
+	case ')', '>', ']', '{', '}':
+		return nil, fmt.Errorf("unexpected %q at offset %d", c, p.pos)
+	}
+
+	start := p.pos
+	token := p.keyword()
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		f, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected %q at offset %d", token, start)
+		}
+		return f, nil
+	}
+
+	// An integer may start a reference, "12 0 R"
+	next := p.pos
+	if gen, ok := p.integer(); ok && n >= 0 && n <= maxObjectNumber && p.keyword() == "R" {
+		return ref{num: int(n), gen: gen}, nil
+	}
+	p.pos = next
+	return n, nil
+}
+
+func (p *parser) name() name {
+	p.pos++ // the slash
+	var buf []byte
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		c := p.data[p.pos]
+		if c == '#' && p.pos+2 < len(p.data) {
+			if b, ok := unhex(p.data[p.pos+1], p.data[p.pos+2]); ok {
+				buf = append(buf, b)
+				p.pos += 3
+				continue
+			}
+		}
+		buf = append(buf, c)
+		p.pos++
+	}
+	return name(buf)
+}
+
+func (p *parser) dict(depth int) (object, error) {
+	d := dict{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if p.hasPrefix(">>") {
+			p.pos += 2
+			return d, nil
+		}
+		key, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		k, ok := key.(name)
+		if !ok {
+			return nil, fmt.Errorf("dictionary key is not a name at offset %d", p.pos)
+		}
+		value, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		d[k] = value
+	}
+}
+
+func (p *parser) array(depth int) (object, error) {
+	var a array
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return a, nil
+		}
+		value, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, value)
+	}
+}
+
+// literalString reads a string in parentheses, which may contain balanced
+// parentheses and backslash escapes
+func (p *parser) literalString() (object, error) {
+	p.pos++ // the opening parenthesis
+	var buf []byte
+	nesting := 0
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			nesting++
+		case ')':
+			if nesting == 0 {
+				return string(buf), nil
+			}
+			nesting--
+		case '\r':
+			// End-of-line markers in strings read as a line feed
+			if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+				p.pos++
+			}
+			c = '\n'
+		case '\\':
+			if p.pos >= len(p.data) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			c = p.data[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// A backslash before an end-of-line continues the line
+				if c == '\r' && p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						n = n*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		buf = append(buf, c)
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// hexString reads a string of hexadecimal digits in angle brackets
+func (p *parser) hexString() (object, error) {
+	p.pos++ // the opening angle bracket
+	var digits []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch {
+		case c == '>':
+			if len(digits)%2 == 1 {
+				digits = append(digits, '0')
+			}
+			buf := make([]byte, len(digits)/2)
+			for i := range buf {
+				buf[i], _ = unhex(digits[2*i], digits[2*i+1])
+			}
+			return string(buf), nil
+		case isSpace(c):
+		case hexValue(c) >= 0:
+			digits = append(digits, c)
+		default:
+			return nil, fmt.Errorf("invalid hex string at offset %d", p.pos-1)
+		}
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+func unhex(hi, lo byte) (byte, bool) {
+	h, l := hexValue(hi), hexValue(lo)
+	if h < 0 || l < 0 {
+		return 0, false
+	}
+	return byte(h<<4 | l), true
+}
@@ -0,0 +1,261 @@
+// Package pdfmeta reads the document metadata of PDF files: the Info
+// dictionary, the XMP metadata stream and the page count, in pure Go. Only
+// the objects leading to the metadata are read and parsed, and only Flate is
+// decoded, the filter metadata and cross-reference streams use.
+package pdfmeta
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Metadata is what a PDF file tells about itself. Fields the file does not
+// set are left empty.
+type Metadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	// PageCount is 0 when the page tree could not be read
+	PageCount int
+	CreatedAt *time.Time
+}
+
+// ErrNotPDF is returned for content without a PDF header
+var ErrNotPDF = errors.New("not a PDF file")
+
+// Extract reads the metadata of the PDF file r. Values in the Info
+// dictionary win; the XMP metadata fills in what it lacks, which is all of
+// it for PDF 2.0 files. Encrypted files only reveal their page count.
+//
+// The file is not read as a whole: only the cross-reference sections found
+// from its tail and the objects they lead to are.
+func Extract(r io.ReaderAt, size int64) (*Metadata, error) {
+	head := make([]byte, max(min(size, 1024), 0))
+	if n, err := r.ReadAt(head, 0); n < len(head) && err != nil {
+		return nil, err
+	}
+	if !strings.Contains(string(head), "%PDF-") {
+		return nil, ErrNotPDF
+	}
+
+	doc, err := newDocument(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{}
+	root, _ := doc.resolve(doc.trailer["Root"]).(dict)
+	if pages, ok := doc.resolve(root["Pages"]).(dict); ok {
+		if count, ok := doc.resolve(pages["Count"]).(int64); ok && count > 0 && count <= maxObjectNumber {
+			meta.PageCount = int(count)
+		}
+	}
+	if doc.trailer["Encrypt"] != nil {
+		return meta, nil
+	}
+
+	if info, ok := doc.resolve(doc.trailer["Info"]).(dict); ok {
+		meta.Title = doc.text(info["Title"])
+		meta.Author = doc.text(info["Author"])
+		meta.Subject = doc.text(info["Subject"])
+		meta.Keywords = doc.text(info["Keywords"])
+		if created, ok := parseDate(doc.text(info["CreationDate"])); ok {
+			meta.CreatedAt = &created
+		}
+	}
+
+	if s, ok := doc.resolve(root["Metadata"]).(*stream); ok {
+		if packet, err := doc.decode(s); err == nil {
+			meta.fillFromXMP(parseXMP(packet))
+		}
+	}
+
+	return meta, nil
+}
+
+func (m *Metadata) fillFromXMP(props map[xml.Name][]string) {
+	first := func(values []string) string {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+	if m.Title == "" {
+		m.Title = clean(first(props[xmpTitle]))
+	}
+	if m.Author == "" {
+		m.Author = clean(strings.Join(props[xmpCreator], ", "))
+	}
+	if m.Subject == "" {
+		m.Subject = clean(first(props[xmpDescription]))
+	}
+	if m.Keywords == "" {
+		m.Keywords = clean(first(props[xmpKeywords]))
+	}
+	if m.Keywords == "" {
+		m.Keywords = clean(strings.Join(props[xmpSubject], ", "))
+	}
+	if m.CreatedAt == nil {
+		if created, ok := parseXMPDate(first(props[xmpCreateDate])); ok {
+			m.CreatedAt = &created
+		}
+	}
+}
+
+// text decodes a PDF text string, which is UTF-16BE or UTF-8 behind a byte
+// order mark and PDFDocEncoding otherwise
+func (d *document) text(obj object) string {
+	s, ok := d.resolve(obj).(string)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(s, "\xfe\xff"):
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		s = string(utf16.Decode(units))
+	case strings.HasPrefix(s, "\xef\xbb\xbf"):
+		s = strings.ToValidUTF8(s[3:], "")
+	default:
+		var b strings.Builder
+		for i := 0; i < len(s); i++ {
+			b.WriteRune(pdfDocRune(s[i]))
+		}
+		s = b.String()
+	}
+	return clean(s)
+}
+
+// clean drops control characters, such as the language escapes of
+// Unicode text strings, and collapses white space
+func clean(s string) string {
+	// A language escape sequence is ESC, a language code and ESC
+	for {
+		start := strings.IndexRune(s, '\x1b')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexRune(s[start+1:], '\x1b')
+		if end < 0 {
+			s = s[:start]
+			break
+		}
+		s = s[:start] + s[start+1+end+1:]
+	}
+	s = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && !unicode.IsSpace(r)) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// pdfDocHigh maps the bytes 0x80 to 0x9E of PDFDocEncoding, which differ
+// from Latin-1
+var pdfDocHigh = []rune("•†‡…—–ƒ⁄‹›−‰„“”‘’‚™ﬁﬂŁŒŠŸŽıłœšž")
+
+// pdfDocLow maps the bytes 0x18 to 0x1F of PDFDocEncoding, accents
+var pdfDocLow = []rune("˘ˇˆ˙˝˛˚˜")
+
+func pdfDocRune(c byte) rune {
+	switch {
+	case c >= 0x18 && c <= 0x1f:
+		return pdfDocLow[c-0x18]
+	case c >= 0x80 && c <= 0x9e:
+		return pdfDocHigh[c-0x80]
+	case c == 0x9f || c == 0xad:
+		return utf8.RuneError
+	case c == 0xa0:
+		return '€'
+	}
+	return rune(c)
+}
+
+// parseDate reads a PDF date, "D:YYYYMMDDHHmmSSOHH'mm'", where everything
+// after the year is optional. Dates without a time zone are taken as UTC.
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+
+	number := func(digits int) (int, bool) {
+		if len(s) < digits {
+			return 0, false
+		}
+		n, err := strconv.Atoi(s[:digits])
+		if err != nil {
+			return 0, false
+		}
+		s = s[digits:]
+		return n, true
+	}
+
+	year, ok := number(4)
+	if !ok {
+		return time.Time{}, false
+	}
+	fields := []int{1, 1, 0, 0, 0} // month, day, hour, minute, second
+	for i := range fields {
+		n, ok := number(2)
+		if !ok {
+			break
+		}
+		fields[i] = n
+	}
+	month, day, hour, minute, second := fields[0], fields[1], fields[2], fields[3], fields[4]
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+
+	location := time.UTC
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		sign := 1
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+		hours, _ := number(2)
+		s = strings.TrimPrefix(s, "'")
+		minutes, _ := number(2)
+		if hours <= 23 && minutes <= 59 {
+			location = time.FixedZone("", sign*(hours*3600+minutes*60))
+		}
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, location)
+	if t.Day() != day {
+		return time.Time{}, false // e.g. February 30
+	}
+	return t.UTC(), true
+}
+
+// xmpDateLayouts are the ISO 8601 forms XMP dates come in
+var xmpDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+func parseXMPDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range xmpDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
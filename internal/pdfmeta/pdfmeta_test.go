@@ -0,0 +1,285 @@
+package pdfmeta
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const xmpPacket = `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+  <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+    <rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" pdf:Keywords="go, concurrency"/>
+    <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/">
+      <dc:title><rdf:Alt>
+        <rdf:li xml:lang="de">Nebenläufigkeit in Go</rdf:li>
+        <rdf:li xml:lang="x-default">Concurrency in Go</rdf:li>
+      </rdf:Alt></dc:title>
+      <dc:creator><rdf:Seq><rdf:li>Katherine Cox-Buday</rdf:li><rdf:li>Jane Doe</rdf:li></rdf:Seq></dc:creator>
+      <dc:description><rdf:Alt><rdf:li xml:lang="x-default">Tools and techniques</rdf:li></rdf:Alt></dc:description>
+      <xmp:CreateDate>2017-07-19T10:30:00+02:00</xmp:CreateDate>
+    </rdf:Description>
+  </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func TestExtractInfoDictionary(t *testing.T) {
+	pdf := classicPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 3 >>",
+		`<< /Title <FEFF004700F600640065006C> /Author (Fran\347ois \(ed.\)\222s) /Subject (A  tale\r\nin two lines)
+		   /Keywords (novel, classic) /CreationDate (D:20200131235959-05'30') /Producer (test) >>`,
+	}, "/Root 1 0 R /Info 3 0 R")
+
+	meta := extract(t, pdf)
+	created := time.Date(2020, 2, 1, 5, 29, 59, 0, time.UTC)
+	want := Metadata{
+		Title:     "Gödel",
+		Author:    "François (ed.)™s",
+		Subject:   "A tale in two lines",
+		Keywords:  "novel, classic",
+		PageCount: 3,
+		CreatedAt: &created,
+	}
+	assertMetadata(t, meta, want)
+}
+
+func TestExtractXMP(t *testing.T) {
+	pdf := classicPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 3 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 224 >>",
+		streamObject("/Type /Metadata /Subtype /XML", []byte(xmpPacket), false),
+		"<< /Title () /Author (Info Author) >>",
+	}, "/Root 1 0 R /Info 4 0 R")
+
+	meta := extract(t, pdf)
+	created := time.Date(2017, 7, 19, 8, 30, 0, 0, time.UTC)
+	assertMetadata(t, meta, Metadata{
+		Title:     "Concurrency in Go",
+		Author:    "Info Author",
+		Subject:   "Tools and techniques",
+		Keywords:  "go, concurrency",
+		PageCount: 224,
+		CreatedAt: &created,
+	})
+}
+
+// PDF 1.5 files keep most objects in compressed object streams, listed by
+// a cross-reference stream with a PNG predictor
+func TestExtractObjectStreams(t *testing.T) {
+	members := []string{
+		"<< /Type /Catalog /Pages 3 0 R /Metadata 5 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 12 >>",
+		"<< /Title (Compressed) >>",
+	}
+	var header, body bytes.Buffer
+	for i, member := range members {
+		fmt.Fprintf(&header, "%d %d ", i+2, body.Len())
+		body.WriteString(member + "\n")
+	}
+	objStm := append(header.Bytes(), body.Bytes()...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := map[int]int{}
+	offsets[1] = buf.Len()
+	buf.WriteString("1 0 obj\n" + streamObject(fmt.Sprintf("/Type /ObjStm /N 3 /First %d", header.Len()), objStm, true) + "\nendobj\n")
+	offsets[5] = buf.Len()
+	buf.WriteString("5 0 obj\n" + streamObject("/Type /Metadata /Subtype /XML", []byte(xmpPacket), true) + "\nendobj\n")
+
+	// Rows of type (1 byte), offset or stream number (2), index (1)
+	rows := [][]int{{0, 0, 255}, {1, offsets[1], 0}, {2, 1, 0}, {2, 1, 1}, {2, 1, 2}, {1, offsets[5], 0}}
+	xrefOffset := buf.Len()
+	rows = append(rows, []int{1, xrefOffset, 0})
+	var raw, prev []byte
+	for _, row := range rows {
+		cur := []byte{byte(row[0]), byte(row[1] >> 8), byte(row[1]), byte(row[2])}
+		raw = append(raw, 2) // the Up filter
+		for i := range cur {
+			var up byte
+			if prev != nil {
+				up = prev[i]
+			}
+			raw = append(raw, cur[i]-up)
+		}
+		prev = cur
+	}
+	buf.WriteString("6 0 obj\n" + streamObject("/Type /XRef /Size 7 /W [1 2 1] /Root 2 0 R /Info 4 0 R /DecodeParms << /Predictor 12 /Columns 4 >>", raw, true) + "\nendobj\n")
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	meta := extract(t, buf.Bytes())
+	if meta.Title != "Compressed" || meta.Author != "Katherine Cox-Buday, Jane Doe" || meta.PageCount != 12 {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+}
+
+func TestExtractRepairsBrokenXref(t *testing.T) {
+	pdf := classicPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 7 >>",
+		"<< /Title (Shifted) >>",
+	}, "/Root 1 0 R /Info 3 0 R")
+	// Content added in front of the objects invalidates every offset
+	pdf = bytes.Replace(pdf, []byte("%PDF-1.4\n"), []byte("%PDF-1.4\n% a comment some editor added\n"), 1)
+
+	meta := extract(t, pdf)
+	if meta.Title != "Shifted" || meta.PageCount != 7 {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+}
+
+func TestExtractEncrypted(t *testing.T) {
+	pdf := classicPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 5 >>",
+		"<< /Title (\x8a\x01\xf3) >>",
+		"<< /Filter /Standard /V 2 /R 3 >>",
+	}, "/Root 1 0 R /Info 3 0 R /Encrypt 4 0 R")
+
+	meta := extract(t, pdf)
+	assertMetadata(t, meta, Metadata{PageCount: 5})
+}
+
+func TestExtractInvalidContent(t *testing.T) {
+	if _, err := Extract(bytes.NewReader([]byte("PK\x03\x04")), 4); !errors.Is(err, ErrNotPDF) {
+		t.Errorf("expected ErrNotPDF, got %v", err)
+	}
+
+	// Truncated and corrupted files must fail or come back partial, never
+	// panic or hang
+	pdf := classicPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 3 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 1 /Self 2 0 R >>",
+		streamObject("/Type /Metadata /Length 3 0 R", []byte(xmpPacket), true),
+		"<< /Title (\\(((x)) /Author <4> /Next [[[[]]]] >>",
+	}, "/Root 1 0 R /Info 4 0 R")
+	for n := 0; n < len(pdf); n += 7 {
+		Extract(bytes.NewReader(pdf[:n]), int64(n))
+		corrupted := bytes.Clone(pdf)
+		corrupted[n] ^= 0xff
+		Extract(bytes.NewReader(corrupted), int64(len(corrupted)))
+	}
+
+	nested := []byte("%PDF-1.4\n1 0 obj\n" + string(bytes.Repeat([]byte("["), 100000)) + "\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF")
+	Extract(bytes.NewReader(nested), int64(len(nested)))
+}
+
+// countingReader records how many bytes were read from it
+type countingReader struct {
+	r    *bytes.Reader
+	read int
+}
+
+func (c *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += n
+	return n, err
+}
+
+func TestExtractReadsOnlyWhatItNeeds(t *testing.T) {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 9 >>",
+		// Larger than the first window read for an object
+		"<< /Title (" + strings.Repeat("Long ", 2000) + ") >>",
+		// The page content, which is never looked at
+		streamObject("", bytes.Repeat([]byte("0 0 m 1 1 l S\n"), 1<<18), false),
+	}
+	// Enough objects for the cross-reference table to outgrow the first window
+	for range 1000 {
+		objects = append(objects, "null")
+	}
+	pdf := classicPDF(objects, "/Root 1 0 R /Info 3 0 R")
+
+	r := &countingReader{r: bytes.NewReader(pdf)}
+	meta, err := Extract(r, int64(len(pdf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != strings.TrimSpace(strings.Repeat("Long ", 2000)) || meta.PageCount != 9 {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+	if r.read > len(pdf)/10 {
+		t.Errorf("read %d of %d bytes", r.read, len(pdf))
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"D:20231105143000Z", time.Date(2023, 11, 5, 14, 30, 0, 0, time.UTC), true},
+		{"D:20231105143000+01'00'", time.Date(2023, 11, 5, 13, 30, 0, 0, time.UTC), true},
+		{"D:20231105143000-0800", time.Date(2023, 11, 5, 22, 30, 0, 0, time.UTC), true},
+		{"D:199812", time.Date(1998, 12, 1, 0, 0, 0, 0, time.UTC), true},
+		{"2001", time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"D:20230230", time.Time{}, false},
+		{"D:20231301", time.Time{}, false},
+		{"yesterday", time.Time{}, false},
+	} {
+		got, ok := parseDate(tc.in)
+		if ok != tc.ok || !got.Equal(tc.want) {
+			t.Errorf("parseDate(%q) = %s, %v; expected %s, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func extract(t *testing.T, pdf []byte) *Metadata {
+	t.Helper()
+	meta, err := Extract(bytes.NewReader(pdf), int64(len(pdf)))
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	return meta
+}
+
+func assertMetadata(t *testing.T, got *Metadata, want Metadata) {
+	t.Helper()
+	if got.Title != want.Title || got.Author != want.Author || got.Subject != want.Subject ||
+		got.Keywords != want.Keywords || got.PageCount != want.PageCount {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+	switch {
+	case want.CreatedAt == nil && got.CreatedAt != nil:
+		t.Errorf("expected no creation date, got %s", got.CreatedAt)
+	case want.CreatedAt != nil && (got.CreatedAt == nil || !got.CreatedAt.Equal(*want.CreatedAt)):
+		t.Errorf("expected creation date %s, got %v", want.CreatedAt, got.CreatedAt)
+	}
+}
+
+// classicPDF numbers the objects from 1 and writes a cross-reference table
+// and trailer for them
+func classicPDF(objects []string, trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+func streamObject(entries string, data []byte, compress bool) string {
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write(data)
+		w.Close()
+		data = buf.Bytes()
+		entries += " /Filter /FlateDecode"
+	}
+	return fmt.Sprintf("<< /Length %d %s >>\nstream\n%s\nendstream", len(data), entries, data)
+}
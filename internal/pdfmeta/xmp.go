@@ -0,0 +1,102 @@
+package pdfmeta
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// XML namespaces of the XMP properties that mirror the Info dictionary
+const (
+	nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC  = "http://purl.org/dc/elements/1.1/"
+	nsPDF = "http://ns.adobe.com/pdf/1.3/"
+	nsXMP = "http://ns.adobe.com/xap/1.0/"
+)
+
+var (
+	xmpTitle       = xml.Name{Space: nsDC, Local: "title"}
+	xmpCreator     = xml.Name{Space: nsDC, Local: "creator"}
+	xmpDescription = xml.Name{Space: nsDC, Local: "description"}
+	xmpSubject     = xml.Name{Space: nsDC, Local: "subject"}
+	xmpKeywords    = xml.Name{Space: nsPDF, Local: "Keywords"}
+	xmpCreateDate  = xml.Name{Space: nsXMP, Local: "CreateDate"}
+)
+
+// parseXMP returns the values of the properties in an XMP packet. A
+// property is either simple text, in an attribute or an element, or an
+// rdf:Alt, rdf:Bag or rdf:Seq of items. The x-default item of a language
+// alternative comes first.
+func parseXMP(packet []byte) map[xml.Name][]string {
+	props := make(map[xml.Name][]string)
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+
+	var (
+		depth       int
+		description = -1 // depth of the enclosing rdf:Description
+		property    xml.Name
+		values      []string
+		text        strings.Builder
+		inItem      bool
+		isDefault   bool
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// Keep what was read before malformed XML
+			return props
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case t.Name == xml.Name{Space: nsRDF, Local: "Description"}:
+				description = depth
+				for _, attr := range t.Attr {
+					if attr.Name.Space != "" && attr.Name.Space != nsRDF {
+						props[attr.Name] = append(props[attr.Name], strings.TrimSpace(attr.Value))
+					}
+				}
+			case description >= 0 && depth == description+1:
+				property, values = t.Name, nil
+				text.Reset()
+			case description >= 0 && t.Name == xml.Name{Space: nsRDF, Local: "li"}:
+				inItem, isDefault = true, false
+				text.Reset()
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "lang" && attr.Value == "x-default" {
+						isDefault = true
+					}
+				}
+			}
+		case xml.CharData:
+			if description >= 0 && (inItem || depth == description+1) {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch {
+			case inItem && t.Name == xml.Name{Space: nsRDF, Local: "li"}:
+				inItem = false
+				if value := strings.TrimSpace(text.String()); value != "" {
+					if isDefault {
+						values = append([]string{value}, values...)
+					} else {
+						values = append(values, value)
+					}
+				}
+				text.Reset()
+			case description >= 0 && depth == description+1:
+				if values == nil {
+					if value := strings.TrimSpace(text.String()); value != "" {
+						values = []string{value}
+					}
+				}
+				props[property] = append(props[property], values...)
+			case depth == description:
+				description = -1
+			}
+			depth--
+		}
+	}
+}
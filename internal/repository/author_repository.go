@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"library-project/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// bookAuthorList scans the JSON array of authors selected with bookSelectColumns
+type bookAuthorList []models.BookAuthor
+
+func (l *bookAuthorList) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return errors.New("unsupported type for book authors")
+	}
+	return json.Unmarshal(data, (*[]models.BookAuthor)(l))
+}
+
+// refreshAuthorNamesSQL copies the author names onto the books matched by
+// the condition, keeping the search vector of the books up to date
+const refreshAuthorNamesSQL = `
+    UPDATE books b
+    SET author_names = COALESCE((
+        SELECT string_agg(a.name, ' ' ORDER BY ba.position)
+        FROM book_authors ba
+        JOIN authors a ON a.id = ba.author_id
+        WHERE ba.book_id = b.id
+    ), '')
+    WHERE `
+
+type authorRepository struct {
+	db DBTX
+}
+
+func NewAuthorRepository(db *sql.DB) AuthorRepository {
+	return &authorRepository{db: db}
+}
+
+func (r *authorRepository) Create(ctx context.Context, author *models.Author) error {
+	author.ID = uuid.New().String()
+
+	query := `
+        INSERT INTO authors (id, name, bio)
+        VALUES ($1, $2, $3)
+        RETURNING created_at, updated_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query, author.ID, author.Name, author.Bio).
+		Scan(&author.CreatedAt, &author.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *authorRepository) Update(ctx context.Context, author *models.Author) error {
+	query := `
+        UPDATE authors
+        SET name = $1, bio = $2
+        WHERE id = $3
+        RETURNING created_at, updated_at
+    `
+
+	err := r.db.QueryRowContext(ctx, query, author.Name, author.Bio, author.ID).
+		Scan(&author.CreatedAt, &author.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, refreshAuthorNamesSQL+`b.id IN (SELECT book_id FROM book_authors WHERE author_id = $1)`, author.ID)
+	return err
+}
+
+func (r *authorRepository) Delete(ctx context.Context, id string) error {
+	// The links go with the author, so remember the books to refresh first
+	rows, err := r.db.QueryContext(ctx, `SELECT book_id FROM book_authors WHERE author_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	var bookIDs []string
+	for rows.Next() {
+		var bookID string
+		if err := rows.Scan(&bookID); err != nil {
+			rows.Close()
+			return err
+		}
+		bookIDs = append(bookIDs, bookID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM authors WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = r.db.ExecContext(ctx, refreshAuthorNamesSQL+`b.id = ANY($1)`, pq.Array(bookIDs))
+	return err
+}
+
+func (r *authorRepository) FindByID(ctx context.Context, id string) (*models.Author, error) {
+	author := &models.Author{}
+
+	query := `SELECT id, name, bio, created_at, updated_at FROM authors WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&author.ID, &author.Name, &author.Bio, &author.CreatedAt, &author.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return author, err
+}
+
+func (r *authorRepository) FindOrCreateByName(ctx context.Context, name string) (*models.Author, error) {
+	query := `
+        INSERT INTO authors (id, name)
+        SELECT $1, $2
+        WHERE NOT EXISTS (SELECT 1 FROM authors WHERE LOWER(name) = LOWER($2))
+        ON CONFLICT (name) DO NOTHING
+    `
+	if _, err := r.db.ExecContext(ctx, query, uuid.New().String(), name); err != nil {
+		return nil, err
+	}
+
+	// An exact match wins over authors differing only in case
+	author := &models.Author{}
+	query = `
+        SELECT id, name, bio, created_at, updated_at
+        FROM authors
+        WHERE LOWER(name) = LOWER($1)
+        ORDER BY name = $1 DESC, created_at
+        LIMIT 1
+    `
+	err := r.db.QueryRowContext(ctx, query, name).Scan(
+		&author.ID, &author.Name, &author.Bio, &author.CreatedAt, &author.UpdatedAt,
+	)
+	return author, err
+}
+
+// FindAll returns authors ordered by name with the number of books of each
+func (r *authorRepository) FindAll(ctx context.Context, limit, offset int) ([]*models.AuthorWithBookCount, error) {
+	query := `
+        SELECT a.id, a.name, a.bio, a.created_at, a.updated_at, COUNT(b.id)
+        FROM authors a
+        LEFT JOIN book_authors ba ON ba.author_id = a.id
+        LEFT JOIN books b ON b.id = ba.book_id AND b.deleted_at IS NULL
+        GROUP BY a.id
+        ORDER BY LOWER(a.name), a.id
+        LIMIT $1 OFFSET $2
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []*models.AuthorWithBookCount
+	for rows.Next() {
+		author := &models.AuthorWithBookCount{}
+		err := rows.Scan(&author.ID, &author.Name, &author.Bio,
+			&author.CreatedAt, &author.UpdatedAt, &author.BookCount)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+
+	return authors, rows.Err()
+}
+
+func (r *authorRepository) CountAll(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM authors`).Scan(&count)
+	return count, err
+}
+
+func (r *authorRepository) SetBookAuthors(ctx context.Context, bookID string, authorIDs []string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM book_authors WHERE book_id = $1`, bookID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO book_authors (book_id, author_id, position) VALUES ($1, $2, $3)`
+	for position, authorID := range authorIDs {
+		if _, err := r.db.ExecContext(ctx, query, bookID, authorID, position); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.db.ExecContext(ctx, refreshAuthorNamesSQL+`b.id = $1`, bookID)
+	return err
+}
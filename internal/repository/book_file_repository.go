@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type bookFileRepository struct {
+	db DBTX
+}
+
+func NewBookFileRepository(db *sql.DB) BookFileRepository {
+	return &bookFileRepository{db: db}
+}
+
+func (r *bookFileRepository) Create(ctx context.Context, file *models.BookFile) error {
+	file.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO book_files (id, book_id, version, pdf_file, file_hash, format, page_count, uploaded_by, restored_from)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, NULLIF($6, 0), NULLIF($7, ''), $8
+		FROM book_files
+		WHERE book_id = $2
+		RETURNING version, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, file.ID, file.BookID, file.PDFFile, file.FileHash,
+		file.Format, file.PageCount, file.UploadedBy, file.RestoredFrom).Scan(&file.Version, &file.CreatedAt)
+}
+
+func (r *bookFileRepository) FindByBookID(ctx context.Context, bookID string) ([]*models.BookFile, error) {
+	query := `
+		SELECT id, book_id, version, pdf_file, file_hash, format, COALESCE(page_count, 0), COALESCE(uploaded_by, ''), restored_from, created_at
+		FROM book_files
+		WHERE book_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*models.BookFile
+	for rows.Next() {
+		file := &models.BookFile{}
+		err := rows.Scan(
+			&file.ID, &file.BookID, &file.Version, &file.PDFFile, &file.FileHash, &file.Format, &file.PageCount,
+			&file.UploadedBy, &file.RestoredFrom, &file.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return files, rows.Err()
+}
+
+func (r *bookFileRepository) FindByVersion(ctx context.Context, bookID string, version int) (*models.BookFile, error) {
+	file := &models.BookFile{}
+	query := `
+		SELECT id, book_id, version, pdf_file, file_hash, format, COALESCE(page_count, 0), COALESCE(uploaded_by, ''), restored_from, created_at
+		FROM book_files
+		WHERE book_id = $1 AND version = $2
+	`
+
+	err := r.db.QueryRowContext(ctx, query, bookID, version).Scan(
+		&file.ID, &file.BookID, &file.Version, &file.PDFFile, &file.FileHash, &file.Format, &file.PageCount,
+		&file.UploadedBy, &file.RestoredFrom, &file.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
@@ -1,34 +1,121 @@
 package repository
 
 import (
+    "context"
     "database/sql"
+    "fmt"
     "library-project/internal/models"
+    "strings"
+    "time"
+    "unicode"
+
     "github.com/google/uuid"
+    "github.com/lib/pq"
 )
 
-type BookRepository struct {
-    db *sql.DB
+const bookSelectColumns = `
+        b.id, b.title, b.description, b.pdf_file, b.file_hash, b.format, b.cover_image, b.category_id, b.owner_id,
+        b.author, b.subject, b.keywords, COALESCE(b.page_count, 0), b.document_created_at,
+        b.like_count, b.dislike_count, b.save_count, b.download_count,
+        b.created_at, b.updated_at, c.name as category_name,
+        COALESCE((
+            SELECT json_agg(json_build_object('id', a.id, 'name', a.name) ORDER BY ba.position)
+            FROM book_authors ba
+            JOIN authors a ON a.id = ba.author_id
+            WHERE ba.book_id = b.id
+        ), '[]'),
+        COALESCE((
+            SELECT array_agg(t.name ORDER BY t.name)
+            FROM book_tags bt
+            JOIN tags t ON t.id = bt.tag_id
+            WHERE bt.book_id = b.id
+        ), '{}')
+`
+
+// bookSortExpressions whitelists the sortable fields and maps them to SQL.
+// User input is never interpolated into ORDER BY directly.
+var bookSortExpressions = map[string]string{
+    "created_at":     "b.created_at",
+    "updated_at":     "b.updated_at",
+    "save_count":     "b.save_count",
+    "title":          "LOWER(b.title)",
+    "like_count":     "b.like_count",
+    "download_count": "b.download_count",
+    "page_count":     "COALESCE(b.page_count, 0)",
+    // Laplace-smoothed like ratio so a single like does not outrank 90 likes out of 100
+    "rating": "(b.like_count + 1.0) / (b.like_count + b.dislike_count + 2.0)",
+}
+
+// BookSort describes the requested ordering of a book listing
+type BookSort struct {
+    Field string
+    Order string
+}
+
+// orderBy builds the ORDER BY clause for the sort, falling back to defaultExpr
+// when no (or an unknown) field is requested. The book ID is always appended
+// as a tie-breaker so that paginated results are stable.
+func (s BookSort) orderBy(defaultExpr string) string {
+    expr, ok := bookSortExpressions[s.Field]
+    if !ok {
+        return "ORDER BY " + defaultExpr + ", b.id ASC"
+    }
+
+    direction := "DESC"
+    switch strings.ToLower(s.Order) {
+    case "asc":
+        direction = "ASC"
+    case "":
+        if s.Field == "title" {
+            direction = "ASC"
+        }
+    }
+
+    return fmt.Sprintf("ORDER BY %s %s, b.id ASC", expr, direction)
+}
+
+// BookSearchFilter holds the optional criteria for BookRepository.Search
+type BookSearchFilter struct {
+    Search     string
+    CategoryID string
+    // MinPages and MaxPages bound the page count when set; books whose
+    // page count is unknown are left out then
+    MinPages   int
+    MaxPages   int
+    // Tags keeps the books with any of the tags, or with all of them when
+    // MatchAllTags is set
+    Tags         []string
+    MatchAllTags bool
+    Sort       BookSort
+    Limit      int
+    Offset     int
+}
+
+type bookRepository struct {
+    db DBTX
 }
 
-func NewBookRepository(db *sql.DB) *BookRepository {
-    return &BookRepository{db: db}
+func NewBookRepository(db *sql.DB) BookRepository {
+    return &bookRepository{db: db}
 }
 
-func (r *BookRepository) Create(book *models.Book) error {
+func (r *bookRepository) Create(ctx context.Context, book *models.Book) error {
     book.ID = uuid.New().String()
     
     query := `
-        INSERT INTO books (id, title, description, pdf_file, category_id, owner_id)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO books (id, title, description, pdf_file, file_hash, format, cover_image, category_id, owner_id,
+            author, subject, keywords, page_count, document_created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, 0), $14)
         RETURNING created_at, updated_at
     `
     
-    return r.db.QueryRow(query, book.ID, book.Title, book.Description,
-        book.PDFFile, book.CategoryID, book.OwnerID).
+    return r.db.QueryRowContext(ctx, query, book.ID, book.Title, book.Description,
+        book.PDFFile, book.FileHash, book.Format, book.CoverImage, book.CategoryID, book.OwnerID,
+        book.Author, book.Subject, book.Keywords, book.PageCount, book.DocumentCreatedAt).
         Scan(&book.CreatedAt, &book.UpdatedAt)
 }
 
-func (r *BookRepository) Update(book *models.Book) error {
+func (r *bookRepository) Update(ctx context.Context, book *models.Book) error {
     query := `
         UPDATE books 
         SET title = $1, description = $2, category_id = $3
@@ -36,32 +123,71 @@ func (r *BookRepository) Update(book *models.Book) error {
         RETURNING updated_at
     `
     
-    return r.db.QueryRow(query, book.Title, book.Description,
+    return r.db.QueryRowContext(ctx, query, book.Title, book.Description,
         book.CategoryID, book.ID).Scan(&book.UpdatedAt)
 }
 
-func (r *BookRepository) Delete(id string) error {
+// UpdateFileHash records the hash of a file uploaded before hashes were stored
+func (r *bookRepository) UpdateFileHash(ctx context.Context, id, hash string) error {
+    query := `UPDATE books SET file_hash = $1 WHERE id = $2`
+    _, err := r.db.ExecContext(ctx, query, hash, id)
+    return err
+}
+
+func (r *bookRepository) UpdateFile(ctx context.Context, file *models.BookFile) error {
+    query := `UPDATE books SET pdf_file = $1, file_hash = $2, format = $3, page_count = NULLIF($4, 0) WHERE id = $5`
+    _, err := r.db.ExecContext(ctx, query, file.PDFFile, file.FileHash, file.Format, file.PageCount, file.BookID)
+    return err
+}
+
+func (r *bookRepository) UpdateCover(ctx context.Context, id, cover string) error {
+    query := `UPDATE books SET cover_image = $1 WHERE id = $2`
+    _, err := r.db.ExecContext(ctx, query, cover, id)
+    return err
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string) error {
+    query := `UPDATE books SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
+    _, err := r.db.ExecContext(ctx, query, id)
+    return err
+}
+
+func (r *bookRepository) Restore(ctx context.Context, id string) error {
+    query := `UPDATE books SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+    result, err := r.db.ExecContext(ctx, query, id)
+    if err != nil {
+        return err
+    }
+
+    restored, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if restored == 0 {
+        return sql.ErrNoRows
+    }
+    return nil
+}
+
+func (r *bookRepository) Purge(ctx context.Context, id string) error {
     query := `DELETE FROM books WHERE id = $1`
-    _, err := r.db.Exec(query, id)
+    _, err := r.db.ExecContext(ctx, query, id)
     return err
 }
 
-func (r *BookRepository) FindByID(id string) (*models.BookWithCategory, error) {
+func (r *bookRepository) FindByID(ctx context.Context, id string) (*models.BookWithCategory, error) {
     book := &models.BookWithCategory{}
     
-    query := `
-        SELECT b.id, b.title, b.description, b.pdf_file, b.category_id, b.owner_id,
-               b.like_count, b.dislike_count, b.save_count, b.created_at, b.updated_at,
-               c.name as category_name
+    query := `SELECT ` + bookSelectColumns + `
         FROM books b
         JOIN categories c ON b.category_id = c.id
-        WHERE b.id = $1
+        WHERE b.id = $1 AND b.deleted_at IS NULL
     `
     
-    err := r.db.QueryRow(query, id).Scan(
-        &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.CategoryID,
-        &book.OwnerID, &book.LikeCount, &book.DislikeCount, &book.SaveCount,
-        &book.CreatedAt, &book.UpdatedAt, &book.CategoryName,
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
+        &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.FileHash, &book.Format, &book.CoverImage, &book.CategoryID,
+        &book.OwnerID, &book.Author, &book.Subject, &book.Keywords, &book.PageCount, &book.DocumentCreatedAt, &book.LikeCount, &book.DislikeCount, &book.SaveCount,
+        &book.DownloadCount, &book.CreatedAt, &book.UpdatedAt, &book.CategoryName, (*bookAuthorList)(&book.Authors), pq.Array(&book.Tags),
     )
     
     if err == sql.ErrNoRows {
@@ -71,19 +197,121 @@ func (r *BookRepository) FindByID(id string) (*models.BookWithCategory, error) {
     return book, err
 }
 
-func (r *BookRepository) FindAll() ([]*models.BookWithCategory, error) {
-    return r.FindAllPaginated(0, 0)
+func (r *bookRepository) FindDeletedByID(ctx context.Context, id string) (*models.Book, error) {
+    query := `SELECT ` + deletedBookColumns + ` FROM books WHERE id = $1 AND deleted_at IS NOT NULL`
+
+    book, err := scanDeletedBook(r.db.QueryRowContext(ctx, query, id))
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+
+    return book, err
 }
 
-func (r *BookRepository) FindAllPaginated(limit, offset int) ([]*models.BookWithCategory, error) {
+// FindDeletedOlderThan returns the books soft deleted more than retention ago,
+// oldest first. The cutoff is computed by the database so it is not affected by
+// clock or time zone differences.
+func (r *bookRepository) FindDeletedOlderThan(ctx context.Context, retention time.Duration) ([]*models.Book, error) {
+    query := `SELECT ` + deletedBookColumns + `
+        FROM books
+        WHERE deleted_at IS NOT NULL AND deleted_at < LOCALTIMESTAMP - make_interval(secs => $1)
+        ORDER BY deleted_at ASC
+    `
+
+    rows, err := r.db.QueryContext(ctx, query, retention.Seconds())
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var books []*models.Book
+    for rows.Next() {
+        book, err := scanDeletedBook(rows)
+        if err != nil {
+            return nil, err
+        }
+        books = append(books, book)
+    }
+
+    return books, rows.Err()
+}
+
+const deletedBookColumns = `id, title, description, pdf_file, cover_image, category_id, owner_id, deleted_at, created_at, updated_at`
+
+func scanDeletedBook(row interface{ Scan(dest ...interface{}) error }) (*models.Book, error) {
+    book := &models.Book{}
+    err := row.Scan(
+        &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.CoverImage, &book.CategoryID,
+        &book.OwnerID, &book.DeletedAt, &book.CreatedAt, &book.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return book, nil
+}
+
+func (r *bookRepository) FindAllFiles(ctx context.Context) ([]string, error) {
     query := `
-        SELECT b.id, b.title, b.description, b.pdf_file, b.category_id, b.owner_id,
-               b.like_count, b.dislike_count, b.save_count, b.created_at, b.updated_at,
-               c.name as category_name
+        SELECT pdf_file FROM books
+        UNION
+        SELECT pdf_file FROM book_files
+    `
+
+    rows, err := r.db.QueryContext(ctx, query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var files []string
+    for rows.Next() {
+        var file string
+        if err := rows.Scan(&file); err != nil {
+            return nil, err
+        }
+        files = append(files, file)
+    }
+
+    return files, rows.Err()
+}
+
+func (r *bookRepository) FindAllCovers(ctx context.Context) ([]string, error) {
+    query := `SELECT cover_image FROM books WHERE cover_image <> ''`
+
+    rows, err := r.db.QueryContext(ctx, query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var covers []string
+    for rows.Next() {
+        var cover string
+        if err := rows.Scan(&cover); err != nil {
+            return nil, err
+        }
+        covers = append(covers, cover)
+    }
+
+    return covers, rows.Err()
+}
+
+func (r *bookRepository) Lock(ctx context.Context, id string) error {
+    query := `SELECT id FROM books WHERE id = $1 FOR UPDATE`
+    _, err := r.db.ExecContext(ctx, query, id)
+    return err
+}
+
+func (r *bookRepository) FindAll(ctx context.Context) ([]*models.BookWithCategory, error) {
+    return r.FindAllPaginated(ctx, 0, 0, BookSort{})
+}
+
+func (r *bookRepository) FindAllPaginated(ctx context.Context, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error) {
+    query := `SELECT ` + bookSelectColumns + `
         FROM books b
         JOIN categories c ON b.category_id = c.id
-        ORDER BY b.save_count DESC
-    `
+        WHERE b.deleted_at IS NULL
+        ` + sort.orderBy("b.save_count DESC")
 
     // Add pagination if limit > 0
     if limit > 0 {
@@ -94,46 +322,28 @@ func (r *BookRepository) FindAllPaginated(limit, offset int) ([]*models.BookWith
     var err error
 
     if limit > 0 {
-        rows, err = r.db.Query(query, limit, offset)
+        rows, err = r.db.QueryContext(ctx, query, limit, offset)
     } else {
-        rows, err = r.db.Query(query)
+        rows, err = r.db.QueryContext(ctx, query)
     }
     if err != nil {
         return nil, err
     }
     defer rows.Close()
-    
-    var books []*models.BookWithCategory
-    for rows.Next() {
-        book := &models.BookWithCategory{}
-        err := rows.Scan(
-            &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.CategoryID,
-            &book.OwnerID, &book.LikeCount, &book.DislikeCount, &book.SaveCount,
-            &book.CreatedAt, &book.UpdatedAt, &book.CategoryName,
-        )
-        if err != nil {
-            return nil, err
-        }
-        books = append(books, book)
-    }
-    
-    return books, nil
+
+    return scanBooks(rows)
 }
 
-func (r *BookRepository) FindByCategory(categoryID string) ([]*models.BookWithCategory, error) {
-    return r.FindByCategoryPaginated(categoryID, 0, 0)
+func (r *bookRepository) FindByCategory(ctx context.Context, categoryID string) ([]*models.BookWithCategory, error) {
+    return r.FindByCategoryPaginated(ctx, categoryID, 0, 0, BookSort{})
 }
 
-func (r *BookRepository) FindByCategoryPaginated(categoryID string, limit, offset int) ([]*models.BookWithCategory, error) {
-    query := `
-        SELECT b.id, b.title, b.description, b.pdf_file, b.category_id, b.owner_id,
-               b.like_count, b.dislike_count, b.save_count, b.created_at, b.updated_at,
-               c.name as category_name
+func (r *bookRepository) FindByCategoryPaginated(ctx context.Context, categoryID string, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error) {
+    query := `SELECT ` + bookSelectColumns + `
         FROM books b
         JOIN categories c ON b.category_id = c.id
-        WHERE b.category_id = $1
-        ORDER BY b.save_count DESC
-    `
+        WHERE b.category_id = $1 AND b.deleted_at IS NULL
+        ` + sort.orderBy("b.save_count DESC")
 
     // Add pagination if limit > 0
     if limit > 0 {
@@ -144,63 +354,249 @@ func (r *BookRepository) FindByCategoryPaginated(categoryID string, limit, offse
     var err error
 
     if limit > 0 {
-        rows, err = r.db.Query(query, categoryID, limit, offset)
+        rows, err = r.db.QueryContext(ctx, query, categoryID, limit, offset)
     } else {
-        rows, err = r.db.Query(query, categoryID)
+        rows, err = r.db.QueryContext(ctx, query, categoryID)
     }
     if err != nil {
         return nil, err
     }
     defer rows.Close()
-    
-    var books []*models.BookWithCategory
-    for rows.Next() {
-        book := &models.BookWithCategory{}
-        err := rows.Scan(
-            &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.CategoryID,
-            &book.OwnerID, &book.LikeCount, &book.DislikeCount, &book.SaveCount,
-            &book.CreatedAt, &book.UpdatedAt, &book.CategoryName,
-        )
-        if err != nil {
-            return nil, err
-        }
-        books = append(books, book)
+
+    return scanBooks(rows)
+}
+
+// FindByAuthorPaginated returns the books written by the author
+func (r *bookRepository) FindByAuthorPaginated(ctx context.Context, authorID string, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error) {
+    query := `SELECT ` + bookSelectColumns + `
+        FROM books b
+        JOIN categories c ON b.category_id = c.id
+        JOIN book_authors ba ON ba.book_id = b.id
+        WHERE ba.author_id = $1 AND b.deleted_at IS NULL
+        ` + sort.orderBy("b.created_at DESC")
+
+    // Add pagination if limit > 0
+    if limit > 0 {
+        query += ` LIMIT $2 OFFSET $3`
     }
-    
-    return books, nil
+
+    var rows *sql.Rows
+    var err error
+
+    if limit > 0 {
+        rows, err = r.db.QueryContext(ctx, query, authorID, limit, offset)
+    } else {
+        rows, err = r.db.QueryContext(ctx, query, authorID)
+    }
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    return scanBooks(rows)
+}
+
+// FindByCategoryTreePaginated returns books in the category or any of its descendant categories
+func (r *bookRepository) FindByCategoryTreePaginated(ctx context.Context, categoryID string, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error) {
+    query := categorySubtreeCTE + `
+        SELECT ` + bookSelectColumns + `
+        FROM books b
+        JOIN categories c ON b.category_id = c.id
+        WHERE b.category_id IN (SELECT id FROM subtree) AND b.deleted_at IS NULL
+        ` + sort.orderBy("b.save_count DESC")
+
+    // Add pagination if limit > 0
+    if limit > 0 {
+        query += ` LIMIT $2 OFFSET $3`
+    }
+
+    var rows *sql.Rows
+    var err error
+
+    if limit > 0 {
+        rows, err = r.db.QueryContext(ctx, query, categoryID, limit, offset)
+    } else {
+        rows, err = r.db.QueryContext(ctx, query, categoryID)
+    }
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    return scanBooks(rows)
 }
 
-func (r *BookRepository) UpdateLikeCount(bookID string) error {
+func (r *bookRepository) UpdateLikeCount(ctx context.Context, bookID string) error {
     query := `
         UPDATE books 
         SET like_count = (SELECT COUNT(*) FROM likes WHERE book_id = $1 AND is_like = true),
             dislike_count = (SELECT COUNT(*) FROM likes WHERE book_id = $1 AND is_like = false)
         WHERE id = $1
     `
-    _, err := r.db.Exec(query, bookID)
+    _, err := r.db.ExecContext(ctx, query, bookID)
     return err
 }
 
-func (r *BookRepository) UpdateSaveCount(bookID string) error {
+func (r *bookRepository) UpdateSaveCount(ctx context.Context, bookID string) error {
     query := `
         UPDATE books
         SET save_count = (SELECT COUNT(*) FROM saved_books WHERE book_id = $1)
         WHERE id = $1
     `
-    _, err := r.db.Exec(query, bookID)
+    _, err := r.db.ExecContext(ctx, query, bookID)
     return err
 }
 
-func (r *BookRepository) CountAll() (int, error) {
+func (r *bookRepository) CountAll(ctx context.Context) (int, error) {
     var count int
-    query := `SELECT COUNT(*) FROM books`
-    err := r.db.QueryRow(query).Scan(&count)
+    query := `SELECT COUNT(*) FROM books WHERE deleted_at IS NULL`
+    err := r.db.QueryRowContext(ctx, query).Scan(&count)
     return count, err
 }
 
-func (r *BookRepository) CountByCategory(categoryID string) (int, error) {
+func (r *bookRepository) CountByCategory(ctx context.Context, categoryID string) (int, error) {
     var count int
-    query := `SELECT COUNT(*) FROM books WHERE category_id = $1`
-    err := r.db.QueryRow(query, categoryID).Scan(&count)
+    query := `SELECT COUNT(*) FROM books WHERE category_id = $1 AND deleted_at IS NULL`
+    err := r.db.QueryRowContext(ctx, query, categoryID).Scan(&count)
     return count, err
-}
\ No newline at end of file
+}
+
+func (r *bookRepository) CountByAuthor(ctx context.Context, authorID string) (int, error) {
+    var count int
+    query := `
+        SELECT COUNT(*) FROM books b
+        JOIN book_authors ba ON ba.book_id = b.id
+        WHERE ba.author_id = $1 AND b.deleted_at IS NULL
+    `
+    err := r.db.QueryRowContext(ctx, query, authorID).Scan(&count)
+    return count, err
+}
+
+// Search finds books matching the filter using PostgreSQL full-text search.
+// Unless an explicit sort is requested, results are ranked by relevance when a
+// search term is given, otherwise by save count.
+// The second return value is the total number of matching books ignoring pagination.
+func (r *bookRepository) Search(ctx context.Context, filter BookSearchFilter) ([]*models.BookWithCategory, int, error) {
+    conditions := []string{"b.deleted_at IS NULL"}
+    var args []interface{}
+
+    tsQuery := buildPrefixTSQuery(filter.Search)
+    if tsQuery != "" {
+        args = append(args, tsQuery)
+        conditions = append(conditions, fmt.Sprintf("b.search_vector @@ to_tsquery('english', $%d)", len(args)))
+    }
+    if filter.CategoryID != "" {
+        args = append(args, filter.CategoryID)
+        conditions = append(conditions, fmt.Sprintf("b.category_id = $%d", len(args)))
+    }
+    if filter.MinPages > 0 {
+        args = append(args, filter.MinPages)
+        conditions = append(conditions, fmt.Sprintf("b.page_count >= $%d", len(args)))
+    }
+    if filter.MaxPages > 0 {
+        args = append(args, filter.MaxPages)
+        conditions = append(conditions, fmt.Sprintf("b.page_count <= $%d", len(args)))
+    }
+    if len(filter.Tags) > 0 {
+        args = append(args, pq.Array(filter.Tags))
+        tagged := fmt.Sprintf(`
+            SELECT bt.book_id FROM book_tags bt
+            JOIN tags t ON t.id = bt.tag_id
+            WHERE t.name = ANY($%d)`, len(args))
+        if filter.MatchAllTags {
+            // Tag names are unique, so a book has all tags when it matches as many
+            args = append(args, len(filter.Tags))
+            tagged += fmt.Sprintf(`
+            GROUP BY bt.book_id
+            HAVING COUNT(*) = $%d`, len(args))
+        }
+        conditions = append(conditions, "b.id IN ("+tagged+")")
+    }
+
+    where := "WHERE " + strings.Join(conditions, " AND ")
+
+    var total int
+    countQuery := `SELECT COUNT(*) FROM books b ` + where
+    if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
+
+    defaultOrder := "b.save_count DESC"
+    if tsQuery != "" {
+        defaultOrder = "ts_rank(b.search_vector, to_tsquery('english', $1)) DESC, b.save_count DESC"
+    }
+    orderBy := filter.Sort.orderBy(defaultOrder)
+
+    query := `SELECT ` + bookSelectColumns + `
+        FROM books b
+        JOIN categories c ON b.category_id = c.id
+        ` + where + `
+        ` + orderBy
+
+    if filter.Limit > 0 {
+        args = append(args, filter.Limit, filter.Offset)
+        query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+    }
+
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+
+    books, err := scanBooks(rows)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return books, total, nil
+}
+
+// buildPrefixTSQuery turns free user input into a tsquery string where every
+// term must match as a prefix, e.g. "go progr" becomes "go:* & progr:*".
+// Characters that have meaning in tsquery syntax are stripped.
+func buildPrefixTSQuery(search string) string {
+    words := strings.FieldsFunc(search, func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+
+    terms := make([]string, 0, len(words))
+    for _, word := range words {
+        terms = append(terms, strings.ToLower(word)+":*")
+    }
+
+    return strings.Join(terms, " & ")
+}
+
+func scanBooks(rows *sql.Rows) ([]*models.BookWithCategory, error) {
+    var books []*models.BookWithCategory
+    for rows.Next() {
+        book := &models.BookWithCategory{}
+        err := rows.Scan(
+            &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.FileHash, &book.Format, &book.CoverImage, &book.CategoryID,
+            &book.OwnerID, &book.Author, &book.Subject, &book.Keywords, &book.PageCount, &book.DocumentCreatedAt, &book.LikeCount, &book.DislikeCount, &book.SaveCount,
+            &book.DownloadCount, &book.CreatedAt, &book.UpdatedAt, &book.CategoryName, (*bookAuthorList)(&book.Authors), pq.Array(&book.Tags),
+        )
+        if err != nil {
+            return nil, err
+        }
+        books = append(books, book)
+    }
+
+    return books, rows.Err()
+}
+
+func (r *bookRepository) CountDeletedByCategory(ctx context.Context, categoryID string) (int, error) {
+    var count int
+    query := `SELECT COUNT(*) FROM books WHERE category_id = $1 AND deleted_at IS NOT NULL`
+    err := r.db.QueryRowContext(ctx, query, categoryID).Scan(&count)
+    return count, err
+}
+
+// CountByCategoryTree counts books in the category and all of its descendant categories
+func (r *bookRepository) CountByCategoryTree(ctx context.Context, categoryID string) (int, error) {
+    var count int
+    query := categorySubtreeCTE + `SELECT COUNT(*) FROM books WHERE category_id IN (SELECT id FROM subtree) AND deleted_at IS NULL`
+    err := r.db.QueryRowContext(ctx, query, categoryID).Scan(&count)
+    return count, err
+}
@@ -1,36 +1,67 @@
 package repository
 
 import (
+    "context"
     "database/sql"
     "library-project/internal/models"
     "github.com/google/uuid"
 )
 
-type CategoryRepository struct {
-    db *sql.DB
+// categorySubtreeCTE selects the IDs of the category $1 and all its descendants
+const categorySubtreeCTE = `
+    WITH RECURSIVE subtree AS (
+        SELECT id FROM categories WHERE id = $1
+        UNION ALL
+        SELECT child.id FROM categories child
+        JOIN subtree ON child.parent_id = subtree.id
+    )
+`
+
+type categoryRepository struct {
+    db DBTX
 }
 
-func NewCategoryRepository(db *sql.DB) *CategoryRepository {
-    return &CategoryRepository{db: db}
+func NewCategoryRepository(db *sql.DB) CategoryRepository {
+    return &categoryRepository{db: db}
 }
 
-func (r *CategoryRepository) Create(category *models.Category) error {
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
     category.ID = uuid.New().String()
     
     query := `
-        INSERT INTO categories (id, name, description)
-        VALUES ($1, $2, $3)
+        INSERT INTO categories (id, name, description, parent_id)
+        VALUES ($1, $2, $3, $4)
         RETURNING created_at, updated_at
     `
     
-    return r.db.QueryRow(query, category.ID, category.Name, category.Description).
+    err := r.db.QueryRowContext(ctx, query, category.ID, category.Name, category.Description, category.ParentID).
+        Scan(&category.CreatedAt, &category.UpdatedAt)
+    if isUniqueViolation(err) {
+        return ErrConflict
+    }
+    return err
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+    query := `
+        UPDATE categories
+        SET name = $1, description = $2, parent_id = $3
+        WHERE id = $4
+        RETURNING created_at, updated_at
+    `
+
+    err := r.db.QueryRowContext(ctx, query, category.Name, category.Description, category.ParentID, category.ID).
         Scan(&category.CreatedAt, &category.UpdatedAt)
+    if isUniqueViolation(err) {
+        return ErrConflict
+    }
+    return err
 }
 
-func (r *CategoryRepository) FindAll() ([]*models.Category, error) {
-    query := `SELECT id, name, description, created_at, updated_at FROM categories ORDER BY name`
+func (r *categoryRepository) FindAll(ctx context.Context) ([]*models.Category, error) {
+    query := `SELECT id, name, description, parent_id, created_at, updated_at FROM categories ORDER BY name`
     
-    rows, err := r.db.Query(query)
+    rows, err := r.db.QueryContext(ctx, query)
     if err != nil {
         return nil, err
     }
@@ -39,7 +70,7 @@ func (r *CategoryRepository) FindAll() ([]*models.Category, error) {
     var categories []*models.Category
     for rows.Next() {
         cat := &models.Category{}
-        err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.UpdatedAt)
+        err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.ParentID, &cat.CreatedAt, &cat.UpdatedAt)
         if err != nil {
             return nil, err
         }
@@ -49,9 +80,40 @@ func (r *CategoryRepository) FindAll() ([]*models.Category, error) {
     return categories, nil
 }
 
-func (r *CategoryRepository) Delete(id string) error {
+// FindAllWithBookCount returns all categories with the number of books in each,
+// computed in a single aggregate query
+func (r *categoryRepository) FindAllWithBookCount(ctx context.Context) ([]*models.CategoryWithBookCount, error) {
+    query := `
+        SELECT c.id, c.name, c.description, c.parent_id, c.created_at, c.updated_at, COUNT(b.id)
+        FROM categories c
+        LEFT JOIN books b ON b.category_id = c.id AND b.deleted_at IS NULL
+        GROUP BY c.id
+        ORDER BY c.name
+    `
+
+    rows, err := r.db.QueryContext(ctx, query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var categories []*models.CategoryWithBookCount
+    for rows.Next() {
+        cat := &models.CategoryWithBookCount{}
+        err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.ParentID,
+            &cat.CreatedAt, &cat.UpdatedAt, &cat.BookCount)
+        if err != nil {
+            return nil, err
+        }
+        categories = append(categories, cat)
+    }
+
+    return categories, rows.Err()
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id string) error {
     query := `DELETE FROM categories WHERE id = $1`
-    result, err := r.db.Exec(query, id)
+    result, err := r.db.ExecContext(ctx, query, id)
     if err != nil {
         return err
     }
@@ -65,13 +127,13 @@ func (r *CategoryRepository) Delete(id string) error {
     return nil
 }
 
-func (r *CategoryRepository) FindByID(id string) (*models.Category, error) {
+func (r *categoryRepository) FindByID(ctx context.Context, id string) (*models.Category, error) {
     category := &models.Category{}
     
-    query := `SELECT id, name, description, created_at, updated_at FROM categories WHERE id = $1`
+    query := `SELECT id, name, description, parent_id, created_at, updated_at FROM categories WHERE id = $1`
     
-    err := r.db.QueryRow(query, id).Scan(
-        &category.ID, &category.Name, &category.Description,
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
+        &category.ID, &category.Name, &category.Description, &category.ParentID,
         &category.CreatedAt, &category.UpdatedAt,
     )
     
@@ -80,4 +142,36 @@ func (r *CategoryRepository) FindByID(id string) (*models.Category, error) {
     }
     
     return category, err
-}
\ No newline at end of file
+}
+
+func (r *categoryRepository) FindByName(ctx context.Context, name string) (*models.Category, error) {
+    category := &models.Category{}
+
+    query := `SELECT id, name, description, parent_id, created_at, updated_at FROM categories WHERE LOWER(name) = LOWER($1)`
+
+    err := r.db.QueryRowContext(ctx, query, name).Scan(
+        &category.ID, &category.Name, &category.Description, &category.ParentID,
+        &category.CreatedAt, &category.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+
+    return category, err
+}
+
+func (r *categoryRepository) CountChildren(ctx context.Context, id string) (int, error) {
+    var count int
+    query := `SELECT COUNT(*) FROM categories WHERE parent_id = $1`
+    err := r.db.QueryRowContext(ctx, query, id).Scan(&count)
+    return count, err
+}
+
+// IsInSubtree reports whether candidateID is rootID itself or one of its descendants
+func (r *categoryRepository) IsInSubtree(ctx context.Context, rootID, candidateID string) (bool, error) {
+    var exists bool
+    query := categorySubtreeCTE + `SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`
+    err := r.db.QueryRowContext(ctx, query, rootID, candidateID).Scan(&exists)
+    return exists, err
+}
@@ -1,54 +1,227 @@
 package repository
 
 import (
+    "context"
     "database/sql"
     "library-project/internal/models"
+    "time"
+
     "github.com/google/uuid"
+    "github.com/lib/pq"
 )
 
-type CommentRepository struct {
-    db *sql.DB
+type commentRepository struct {
+    db DBTX
 }
 
-func NewCommentRepository(db *sql.DB) *CommentRepository {
-    return &CommentRepository{db: db}
+func NewCommentRepository(db *sql.DB) CommentRepository {
+    return &commentRepository{db: db}
 }
 
-func (r *CommentRepository) Create(comment *models.Comment) error {
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
     comment.ID = uuid.New().String()
     
     query := `
-        INSERT INTO comments (id, book_id, user_id, content, parent_id)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO comments (id, book_id, user_id, content, parent_id, depth)
+        VALUES ($1, $2, $3, $4, $5, $6)
         RETURNING created_at, updated_at
     `
     
-    return r.db.QueryRow(query, comment.ID, comment.BookID, comment.UserID,
-        comment.Content, comment.ParentID).Scan(&comment.CreatedAt, &comment.UpdatedAt)
+    return r.db.QueryRowContext(ctx, query, comment.ID, comment.BookID, comment.UserID,
+        comment.Content, comment.ParentID, comment.Depth).Scan(&comment.CreatedAt, &comment.UpdatedAt)
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id string) (*models.Comment, error) {
+    comment := &models.Comment{}
+
+    query := `
+        SELECT id, book_id, user_id, content, parent_id, depth, like_count, dislike_count,
+               deleted_at, created_at, updated_at
+        FROM comments WHERE id = $1
+    `
+
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
+        &comment.ID, &comment.BookID, &comment.UserID, &comment.Content,
+        &comment.ParentID, &comment.Depth, &comment.LikeCount, &comment.DislikeCount,
+        &comment.DeletedAt, &comment.CreatedAt, &comment.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+
+    return comment, err
+}
+
+func (r *commentRepository) Lock(ctx context.Context, id string) error {
+    query := `SELECT id FROM comments WHERE id = $1 FOR UPDATE`
+    _, err := r.db.ExecContext(ctx, query, id)
+    return err
+}
+
+// Update changes the content of a comment as long as it was created less than
+// editWindow ago. The age is checked by the database so it is not affected by
+// clock or time zone differences; sql.ErrNoRows is returned once the window has passed.
+func (r *commentRepository) Update(ctx context.Context, comment *models.Comment, editWindow time.Duration) error {
+    query := `
+        UPDATE comments
+        SET content = $1
+        WHERE id = $2 AND created_at >= LOCALTIMESTAMP - make_interval(secs => $3)
+        RETURNING updated_at
+    `
+
+    return r.db.QueryRowContext(ctx, query, comment.Content, comment.ID, editWindow.Seconds()).Scan(&comment.UpdatedAt)
 }
 
-func (r *CommentRepository) FindByBookID(bookID string) ([]*models.CommentWithUser, error) {
+func (r *commentRepository) Delete(ctx context.Context, id string) error {
+    query := `DELETE FROM comments WHERE id = $1`
+    _, err := r.db.ExecContext(ctx, query, id)
+    return err
+}
+
+// SoftDelete turns the comment into a tombstone so its replies stay attached to the thread
+func (r *commentRepository) SoftDelete(ctx context.Context, id string) error {
+    query := `UPDATE comments SET content = $1, deleted_at = CURRENT_TIMESTAMP WHERE id = $2`
+    _, err := r.db.ExecContext(ctx, query, models.DeletedCommentContent, id)
+    return err
+}
+
+func (r *commentRepository) UpdateLikeCount(ctx context.Context, commentID string) error {
     query := `
-        SELECT c.id, c.book_id, c.user_id, c.content, c.parent_id, c.created_at, c.updated_at,
-               u.first_name, u.last_name
+        UPDATE comments
+        SET like_count = (SELECT COUNT(*) FROM comment_likes WHERE comment_id = $1 AND is_like = true),
+            dislike_count = (SELECT COUNT(*) FROM comment_likes WHERE comment_id = $1 AND is_like = false)
+        WHERE id = $1
+    `
+    _, err := r.db.ExecContext(ctx, query, commentID)
+    return err
+}
+
+func (r *commentRepository) CountReplies(ctx context.Context, id string) (int, error) {
+    var count int
+    query := `SELECT COUNT(*) FROM comments WHERE parent_id = $1`
+    err := r.db.QueryRowContext(ctx, query, id).Scan(&count)
+    return count, err
+}
+
+func (r *commentRepository) FindByBookID(ctx context.Context, bookID string) ([]*models.CommentWithUser, error) {
+    query := `
+        SELECT ` + commentSelectColumns + `
         FROM comments c
         JOIN users u ON c.user_id = u.id
         WHERE c.book_id = $1
         ORDER BY c.created_at DESC
     `
     
-    rows, err := r.db.Query(query, bookID)
+    rows, err := r.db.QueryContext(ctx, query, bookID)
     if err != nil {
         return nil, err
     }
     defer rows.Close()
     
+    return scanComments(rows)
+}
+
+// FindThreadsByBookID returns a page of top-level comments of a book.
+// sort is one of "newest" (default), "oldest" or "top".
+func (r *commentRepository) FindThreadsByBookID(ctx context.Context, bookID, sort string, limit, offset int) ([]*models.CommentWithUser, error) {
+    query := `
+        SELECT ` + commentSelectColumns + `
+        FROM comments c
+        JOIN users u ON c.user_id = u.id
+        WHERE c.book_id = $1 AND c.parent_id IS NULL
+        ORDER BY ` + commentOrder(sort, false) + `
+        LIMIT $2 OFFSET $3
+    `
+
+    rows, err := r.db.QueryContext(ctx, query, bookID, limit, offset)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    return scanComments(rows)
+}
+
+// FindReplies returns every descendant of the given comments. Replies are
+// oldest first, or highest scored first when sort is "top".
+func (r *commentRepository) FindReplies(ctx context.Context, parentIDs []string, sort string) ([]*models.CommentWithUser, error) {
+    if len(parentIDs) == 0 {
+        return nil, nil
+    }
+
+    query := `
+        WITH RECURSIVE replies AS (
+            SELECT id FROM comments WHERE parent_id = ANY($1)
+            UNION ALL
+            SELECT child.id FROM comments child
+            JOIN replies ON child.parent_id = replies.id
+        )
+        SELECT ` + commentSelectColumns + `
+        FROM comments c
+        JOIN users u ON c.user_id = u.id
+        WHERE c.id IN (SELECT id FROM replies)
+        ORDER BY ` + commentOrder(sort, true) + `
+    `
+
+    rows, err := r.db.QueryContext(ctx, query, pq.Array(parentIDs))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    return scanComments(rows)
+}
+
+func (r *commentRepository) CountThreadsByBookID(ctx context.Context, bookID string) (int, error) {
+    var count int
+    query := `SELECT COUNT(*) FROM comments WHERE book_id = $1 AND parent_id IS NULL`
+    err := r.db.QueryRowContext(ctx, query, bookID).Scan(&count)
+    return count, err
+}
+
+func (r *commentRepository) CountByBookID(ctx context.Context, bookID string) (int, error) {
+    var count int
+    query := `SELECT COUNT(*) FROM comments WHERE book_id = $1`
+    err := r.db.QueryRowContext(ctx, query, bookID).Scan(&count)
+    return count, err
+}
+
+const commentSelectColumns = `
+        c.id, c.book_id, c.user_id, c.content, c.parent_id, c.depth, c.like_count, c.dislike_count,
+        c.deleted_at, c.created_at, c.updated_at, u.first_name, u.last_name
+`
+
+// commentOrders whitelists the supported comment orderings. Threads use the
+// first expression, replies within a thread the second.
+var commentOrders = map[string][2]string{
+    "newest": {"c.created_at DESC, c.id ASC", "c.created_at ASC, c.id ASC"},
+    "oldest": {"c.created_at ASC, c.id ASC", "c.created_at ASC, c.id ASC"},
+    "top": {
+        "(c.like_count - c.dislike_count) DESC, c.created_at DESC, c.id ASC",
+        "(c.like_count - c.dislike_count) DESC, c.created_at ASC, c.id ASC",
+    },
+}
+
+func commentOrder(sort string, replies bool) string {
+    order, ok := commentOrders[sort]
+    if !ok {
+        order = commentOrders["newest"]
+    }
+    if replies {
+        return order[1]
+    }
+    return order[0]
+}
+
+func scanComments(rows *sql.Rows) ([]*models.CommentWithUser, error) {
     var comments []*models.CommentWithUser
     for rows.Next() {
         comment := &models.CommentWithUser{}
         err := rows.Scan(
             &comment.ID, &comment.BookID, &comment.UserID, &comment.Content,
-            &comment.ParentID, &comment.CreatedAt, &comment.UpdatedAt,
+            &comment.ParentID, &comment.Depth, &comment.LikeCount, &comment.DislikeCount,
+            &comment.DeletedAt, &comment.CreatedAt, &comment.UpdatedAt,
             &comment.UserFirstName, &comment.UserLastName,
         )
         if err != nil {
@@ -56,19 +229,19 @@ func (r *CommentRepository) FindByBookID(bookID string) ([]*models.CommentWithUs
         }
         comments = append(comments, comment)
     }
-    
-    return comments, nil
+
+    return comments, rows.Err()
 }
 
-type LikeRepository struct {
-    db *sql.DB
+type likeRepository struct {
+    db DBTX
 }
 
-func NewLikeRepository(db *sql.DB) *LikeRepository {
-    return &LikeRepository{db: db}
+func NewLikeRepository(db *sql.DB) LikeRepository {
+    return &likeRepository{db: db}
 }
 
-func (r *LikeRepository) Upsert(like *models.Like) error {
+func (r *likeRepository) Upsert(ctx context.Context, like *models.Like) error {
     like.ID = uuid.New().String()
     
     query := `
@@ -79,25 +252,82 @@ func (r *LikeRepository) Upsert(like *models.Like) error {
         RETURNING created_at
     `
     
-    return r.db.QueryRow(query, like.ID, like.BookID, like.UserID, like.IsLike).
+    return r.db.QueryRowContext(ctx, query, like.ID, like.BookID, like.UserID, like.IsLike).
         Scan(&like.CreatedAt)
 }
 
-func (r *LikeRepository) Delete(userID, bookID string) error {
+func (r *likeRepository) Delete(ctx context.Context, userID, bookID string) error {
     query := `DELETE FROM likes WHERE user_id = $1 AND book_id = $2`
-    _, err := r.db.Exec(query, userID, bookID)
+    _, err := r.db.ExecContext(ctx, query, userID, bookID)
+    return err
+}
+
+// FindUserLikes returns the user's vote on each of the given books that they
+// liked or disliked, keyed by book ID (true = like, false = dislike)
+func (r *likeRepository) FindUserLikes(ctx context.Context, userID string, bookIDs []string) (map[string]bool, error) {
+    likes := make(map[string]bool)
+    if len(bookIDs) == 0 {
+        return likes, nil
+    }
+
+    query := `SELECT book_id, is_like FROM likes WHERE user_id = $1 AND book_id = ANY($2)`
+
+    rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(bookIDs))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var bookID string
+        var isLike bool
+        if err := rows.Scan(&bookID, &isLike); err != nil {
+            return nil, err
+        }
+        likes[bookID] = isLike
+    }
+
+    return likes, rows.Err()
+}
+
+type commentLikeRepository struct {
+    db DBTX
+}
+
+func NewCommentLikeRepository(db *sql.DB) CommentLikeRepository {
+    return &commentLikeRepository{db: db}
+}
+
+func (r *commentLikeRepository) Upsert(ctx context.Context, like *models.CommentLike) error {
+    like.ID = uuid.New().String()
+
+    query := `
+        INSERT INTO comment_likes (id, comment_id, user_id, is_like)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id, comment_id)
+        DO UPDATE SET is_like = $4
+        RETURNING created_at
+    `
+
+    return r.db.QueryRowContext(ctx, query, like.ID, like.CommentID, like.UserID, like.IsLike).
+        Scan(&like.CreatedAt)
+}
+
+func (r *commentLikeRepository) Delete(ctx context.Context, userID, commentID string) error {
+    query := `DELETE FROM comment_likes WHERE user_id = $1 AND comment_id = $2`
+    _, err := r.db.ExecContext(ctx, query, userID, commentID)
     return err
 }
 
-type SavedBookRepository struct {
-    db *sql.DB
+type savedBookRepository struct {
+    db DBTX
 }
 
-func NewSavedBookRepository(db *sql.DB) *SavedBookRepository {
-    return &SavedBookRepository{db: db}
+func NewSavedBookRepository(db *sql.DB) SavedBookRepository {
+    return &savedBookRepository{db: db}
 }
 
-func (r *SavedBookRepository) Create(saved *models.SavedBook) error {
+func (r *savedBookRepository) Create(ctx context.Context, saved *models.SavedBook) error {
     saved.ID = uuid.New().String()
     
     query := `
@@ -107,47 +337,55 @@ func (r *SavedBookRepository) Create(saved *models.SavedBook) error {
         RETURNING created_at
     `
     
-    return r.db.QueryRow(query, saved.ID, saved.UserID, saved.BookID).
+    return r.db.QueryRowContext(ctx, query, saved.ID, saved.UserID, saved.BookID).
         Scan(&saved.CreatedAt)
 }
 
-func (r *SavedBookRepository) Delete(userID, bookID string) error {
+func (r *savedBookRepository) Delete(ctx context.Context, userID, bookID string) error {
     query := `DELETE FROM saved_books WHERE user_id = $1 AND book_id = $2`
-    _, err := r.db.Exec(query, userID, bookID)
+    _, err := r.db.ExecContext(ctx, query, userID, bookID)
     return err
 }
 
-func (r *SavedBookRepository) FindByUserID(userID string) ([]*models.BookWithCategory, error) {
-    query := `
-        SELECT b.id, b.title, b.description, b.pdf_file, b.category_id, b.owner_id,
-               b.like_count, b.dislike_count, b.save_count, b.created_at, b.updated_at,
-               c.name as category_name
-        FROM saved_books sb
-        JOIN books b ON sb.book_id = b.id
-        JOIN categories c ON b.category_id = c.id
-        WHERE sb.user_id = $1
-        ORDER BY sb.created_at DESC
-    `
-    
-    rows, err := r.db.Query(query, userID)
+// FindSavedBookIDs returns which of the given books the user has saved
+func (r *savedBookRepository) FindSavedBookIDs(ctx context.Context, userID string, bookIDs []string) (map[string]bool, error) {
+    saved := make(map[string]bool)
+    if len(bookIDs) == 0 {
+        return saved, nil
+    }
+
+    query := `SELECT book_id FROM saved_books WHERE user_id = $1 AND book_id = ANY($2)`
+
+    rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(bookIDs))
     if err != nil {
         return nil, err
     }
     defer rows.Close()
-    
-    var books []*models.BookWithCategory
+
     for rows.Next() {
-        book := &models.BookWithCategory{}
-        err := rows.Scan(
-            &book.ID, &book.Title, &book.Description, &book.PDFFile, &book.CategoryID,
-            &book.OwnerID, &book.LikeCount, &book.DislikeCount, &book.SaveCount,
-            &book.CreatedAt, &book.UpdatedAt, &book.CategoryName,
-        )
-        if err != nil {
+        var bookID string
+        if err := rows.Scan(&bookID); err != nil {
             return nil, err
         }
-        books = append(books, book)
+        saved[bookID] = true
     }
-    
-    return books, nil
+
+    return saved, rows.Err()
+}
+
+func (r *savedBookRepository) FindByUserID(ctx context.Context, userID string, sort BookSort) ([]*models.BookWithCategory, error) {
+    query := `SELECT ` + bookSelectColumns + `
+        FROM saved_books sb
+        JOIN books b ON sb.book_id = b.id
+        JOIN categories c ON b.category_id = c.id
+        WHERE sb.user_id = $1 AND b.deleted_at IS NULL
+        ` + sort.orderBy("sb.created_at DESC")
+
+    rows, err := r.db.QueryContext(ctx, query, userID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    return scanBooks(rows)
 }
\ No newline at end of file
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type downloadRepository struct {
+	db DBTX
+}
+
+func NewDownloadRepository(db *sql.DB) DownloadRepository {
+	return &downloadRepository{db: db}
+}
+
+// Create records a download. books.download_count is kept up to date by
+// the trigger_update_download_count trigger.
+func (r *downloadRepository) Create(ctx context.Context, download *models.Download) error {
+	download.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO downloads (id, book_id, user_id, ip_address, user_agent)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING downloaded_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, download.ID, download.BookID, download.UserID,
+		download.IPAddress, download.UserAgent).Scan(&download.DownloadedAt)
+}
+
+func (r *downloadRepository) FindByBookID(ctx context.Context, bookID string, limit, offset int) ([]*models.DownloadWithUser, error) {
+	query := `
+		SELECT d.id, d.book_id, d.user_id, d.downloaded_at,
+		       COALESCE(d.ip_address, ''), COALESCE(d.user_agent, ''),
+		       u.email, u.first_name, u.last_name
+		FROM downloads d
+		JOIN users u ON d.user_id = u.id
+		WHERE d.book_id = $1
+		ORDER BY d.downloaded_at DESC, d.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var downloads []*models.DownloadWithUser
+	for rows.Next() {
+		download := &models.DownloadWithUser{}
+		err := rows.Scan(
+			&download.ID, &download.BookID, &download.UserID, &download.DownloadedAt,
+			&download.IPAddress, &download.UserAgent,
+			&download.UserEmail, &download.UserFirstName, &download.UserLastName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		downloads = append(downloads, download)
+	}
+
+	return downloads, rows.Err()
+}
+
+func (r *downloadRepository) CountByBookID(ctx context.Context, bookID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM downloads WHERE book_id = $1`
+	err := r.db.QueryRowContext(ctx, query, bookID).Scan(&count)
+	return count, err
+}
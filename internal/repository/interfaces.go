@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"library-project/internal/models"
+	"time"
+)
+
+// The interfaces below describe the persistence layer used by the services.
+// The PostgreSQL implementations live in this package; an in-memory
+// implementation for tests lives in the memory subpackage.
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	InvalidateTokens(ctx context.Context, userID string) error
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, rt *models.RefreshToken) error
+	FindByToken(ctx context.Context, token string) (*models.RefreshToken, error)
+	DeleteByToken(ctx context.Context, token string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type BookRepository interface {
+	Create(ctx context.Context, book *models.Book) error
+	Update(ctx context.Context, book *models.Book) error
+	UpdateFileHash(ctx context.Context, id, hash string) error
+	// UpdateFile makes the file version the current file of its book
+	UpdateFile(ctx context.Context, file *models.BookFile) error
+	// UpdateCover sets the storage key prefix of the book's cover thumbnails
+	UpdateCover(ctx context.Context, id, cover string) error
+	// Delete soft deletes the book, hiding it from every lookup and listing
+	// until it is restored or purged
+	Delete(ctx context.Context, id string) error
+	// Restore undoes a soft delete and returns sql.ErrNoRows when the book is not deleted
+	Restore(ctx context.Context, id string) error
+	// Purge permanently removes the book together with everything referencing it
+	Purge(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.BookWithCategory, error)
+	FindDeletedByID(ctx context.Context, id string) (*models.Book, error)
+	// FindDeletedOlderThan returns the books soft deleted more than retention ago, oldest first
+	FindDeletedOlderThan(ctx context.Context, retention time.Duration) ([]*models.Book, error)
+	// FindAllFiles returns the files referenced by any book or book version,
+	// deleted or not
+	FindAllFiles(ctx context.Context) ([]string, error)
+	// FindAllCovers returns the cover key prefixes of all books, deleted or not
+	FindAllCovers(ctx context.Context) ([]string, error)
+	// Lock holds a row lock on the book until the surrounding transaction ends,
+	// serializing concurrent updates of its counters
+	Lock(ctx context.Context, id string) error
+	FindAll(ctx context.Context) ([]*models.BookWithCategory, error)
+	FindAllPaginated(ctx context.Context, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error)
+	FindByCategory(ctx context.Context, categoryID string) ([]*models.BookWithCategory, error)
+	FindByCategoryPaginated(ctx context.Context, categoryID string, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error)
+	FindByCategoryTreePaginated(ctx context.Context, categoryID string, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error)
+	FindByAuthorPaginated(ctx context.Context, authorID string, limit, offset int, sort BookSort) ([]*models.BookWithCategory, error)
+	UpdateLikeCount(ctx context.Context, bookID string) error
+	UpdateSaveCount(ctx context.Context, bookID string) error
+	CountAll(ctx context.Context) (int, error)
+	CountByCategory(ctx context.Context, categoryID string) (int, error)
+	CountByCategoryTree(ctx context.Context, categoryID string) (int, error)
+	// CountDeletedByCategory counts the soft deleted books of the category
+	// that have not been purged yet
+	CountDeletedByCategory(ctx context.Context, categoryID string) (int, error)
+	CountByAuthor(ctx context.Context, authorID string) (int, error)
+	Search(ctx context.Context, filter BookSearchFilter) ([]*models.BookWithCategory, int, error)
+}
+
+// BookFileRepository keeps the version history of the files of books
+type BookFileRepository interface {
+	// Create stores the file as the next version of its book. Callers should
+	// hold the book's lock so concurrent uploads cannot pick the same version.
+	Create(ctx context.Context, file *models.BookFile) error
+	// FindByBookID returns the versions of a book, newest first
+	FindByBookID(ctx context.Context, bookID string) ([]*models.BookFile, error)
+	FindByVersion(ctx context.Context, bookID string, version int) (*models.BookFile, error)
+}
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	Update(ctx context.Context, category *models.Category) error
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context) ([]*models.Category, error)
+	FindAllWithBookCount(ctx context.Context) ([]*models.CategoryWithBookCount, error)
+	FindByID(ctx context.Context, id string) (*models.Category, error)
+	FindByName(ctx context.Context, name string) (*models.Category, error)
+	CountChildren(ctx context.Context, id string) (int, error)
+	IsInSubtree(ctx context.Context, rootID, candidateID string) (bool, error)
+}
+
+type AuthorRepository interface {
+	// Create and Update return ErrConflict when another author has the name
+	Create(ctx context.Context, author *models.Author) error
+	Update(ctx context.Context, author *models.Author) error
+	// Delete returns sql.ErrNoRows when the author does not exist
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Author, error)
+	// FindOrCreateByName returns the author with the name, ignoring case, and
+	// creates it when there is none
+	FindOrCreateByName(ctx context.Context, name string) (*models.Author, error)
+	FindAll(ctx context.Context, limit, offset int) ([]*models.AuthorWithBookCount, error)
+	CountAll(ctx context.Context) (int, error)
+	// SetBookAuthors replaces the authors of a book, keeping their order
+	SetBookAuthors(ctx context.Context, bookID string, authorIDs []string) error
+}
+
+// TagRepository keeps the free-form tags of books. Tags are created when
+// first used; tags no book uses any more are left out of the lookups.
+type TagRepository interface {
+	// SetBookTags replaces the tags of a book, creating the tags that do not exist yet
+	SetBookTags(ctx context.Context, bookID string, names []string) error
+	// FindByPrefix returns the tags starting with prefix together with the number
+	// of books using them, the most used first
+	FindByPrefix(ctx context.Context, prefix string, limit int) ([]*models.TagWithBookCount, error)
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	FindByID(ctx context.Context, id string) (*models.Comment, error)
+	// Lock holds a row lock on the comment until the surrounding transaction ends
+	Lock(ctx context.Context, id string) error
+	Update(ctx context.Context, comment *models.Comment, editWindow time.Duration) error
+	Delete(ctx context.Context, id string) error
+	SoftDelete(ctx context.Context, id string) error
+	UpdateLikeCount(ctx context.Context, commentID string) error
+	CountReplies(ctx context.Context, id string) (int, error)
+	FindByBookID(ctx context.Context, bookID string) ([]*models.CommentWithUser, error)
+	FindThreadsByBookID(ctx context.Context, bookID, sort string, limit, offset int) ([]*models.CommentWithUser, error)
+	FindReplies(ctx context.Context, parentIDs []string, sort string) ([]*models.CommentWithUser, error)
+	CountThreadsByBookID(ctx context.Context, bookID string) (int, error)
+	CountByBookID(ctx context.Context, bookID string) (int, error)
+}
+
+type LikeRepository interface {
+	Upsert(ctx context.Context, like *models.Like) error
+	Delete(ctx context.Context, userID, bookID string) error
+	FindUserLikes(ctx context.Context, userID string, bookIDs []string) (map[string]bool, error)
+}
+
+type CommentLikeRepository interface {
+	Upsert(ctx context.Context, like *models.CommentLike) error
+	Delete(ctx context.Context, userID, commentID string) error
+}
+
+type SavedBookRepository interface {
+	Create(ctx context.Context, saved *models.SavedBook) error
+	Delete(ctx context.Context, userID, bookID string) error
+	FindSavedBookIDs(ctx context.Context, userID string, bookIDs []string) (map[string]bool, error)
+	FindByUserID(ctx context.Context, userID string, sort BookSort) ([]*models.BookWithCategory, error)
+}
+
+type DownloadRepository interface {
+	Create(ctx context.Context, download *models.Download) error
+	FindByBookID(ctx context.Context, bookID string, limit, offset int) ([]*models.DownloadWithUser, error)
+	CountByBookID(ctx context.Context, bookID string) (int, error)
+}
+
+// ReadingRepository keeps track of books read in the web reader
+type ReadingRepository interface {
+	CreateView(ctx context.Context, view *models.BookView) error
+	// UpsertProgress stores the page, replacing any earlier progress of the user on the book
+	UpsertProgress(ctx context.Context, progress *models.ReadingProgress) error
+	FindProgress(ctx context.Context, userID, bookID string) (*models.ReadingProgress, error)
+}
+
+type StatisticsRepository interface {
+	GetTotals(ctx context.Context) (*models.LibraryTotals, error)
+	CountCommentsByBook(ctx context.Context, bookID string) (int, error)
+	CountViewsByBook(ctx context.Context, bookID string) (int, error)
+	// GetBookActivity covers the given number of days up to and including today,
+	// measured by the clock of the database
+	GetBookActivity(ctx context.Context, bookID, interval string, days int) ([]*models.ActivityBucket, error)
+}
+
+// Repositories groups the repositories that can take part in a unit of work
+type Repositories struct {
+	Books        BookRepository
+	BookFiles    BookFileRepository
+	Categories   CategoryRepository
+	Authors      AuthorRepository
+	Tags         TagRepository
+	Comments     CommentRepository
+	Likes        LikeRepository
+	CommentLikes CommentLikeRepository
+	SavedBooks   SavedBookRepository
+	Downloads    DownloadRepository
+}
+
+// UnitOfWork runs a group of repository operations atomically
+type UnitOfWork interface {
+	// Do calls fn with repositories bound to a new transaction. The
+	// transaction is committed if fn returns nil and rolled back otherwise.
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type authorRepository struct {
+	store *Store
+}
+
+func NewAuthorRepository(store *Store) repository.AuthorRepository {
+	return &authorRepository{store: store}
+}
+
+// nameTaken reports whether another author already uses the name. The caller must hold the lock.
+func (r *authorRepository) nameTaken(name, exceptID string) bool {
+	for _, author := range r.store.authors {
+		if author.ID != exceptID && author.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *authorRepository) Create(ctx context.Context, author *models.Author) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.nameTaken(author.Name, "") {
+		return repository.ErrConflict
+	}
+
+	author.ID = uuid.New().String()
+	author.CreatedAt = time.Now()
+	author.UpdatedAt = author.CreatedAt
+
+	stored := *author
+	r.store.authors[author.ID] = &stored
+	return nil
+}
+
+func (r *authorRepository) Update(ctx context.Context, author *models.Author) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.authors[author.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if r.nameTaken(author.Name, author.ID) {
+		return repository.ErrConflict
+	}
+
+	stored.Name = author.Name
+	stored.Bio = author.Bio
+	stored.UpdatedAt = time.Now()
+
+	author.CreatedAt = stored.CreatedAt
+	author.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *authorRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.authors[id]; !ok {
+		return sql.ErrNoRows
+	}
+
+	delete(r.store.authors, id)
+	for key, link := range r.store.bookAuthors {
+		if link.AuthorID == id {
+			delete(r.store.bookAuthors, key)
+		}
+	}
+	return nil
+}
+
+func (r *authorRepository) FindByID(ctx context.Context, id string) (*models.Author, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	author, ok := r.store.authors[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *author
+	return &found, nil
+}
+
+func (r *authorRepository) FindOrCreateByName(ctx context.Context, name string) (*models.Author, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// An exact match wins over authors differing only in case
+	var found *models.Author
+	for _, author := range r.store.authors {
+		if author.Name == name {
+			found = author
+			break
+		}
+		if strings.EqualFold(author.Name, name) && (found == nil || author.CreatedAt.Before(found.CreatedAt)) {
+			found = author
+		}
+	}
+	if found == nil {
+		now := time.Now()
+		found = &models.Author{ID: uuid.New().String(), Name: name, CreatedAt: now, UpdatedAt: now}
+		r.store.authors[found.ID] = found
+	}
+
+	author := *found
+	return &author, nil
+}
+
+func (r *authorRepository) FindAll(ctx context.Context, limit, offset int) ([]*models.AuthorWithBookCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, link := range r.store.bookAuthors {
+		if r.store.books[link.BookID].DeletedAt == nil {
+			counts[link.AuthorID]++
+		}
+	}
+
+	var authors []*models.AuthorWithBookCount
+	for _, author := range r.store.authors {
+		authors = append(authors, &models.AuthorWithBookCount{
+			Author:    *author,
+			BookCount: counts[author.ID],
+		})
+	}
+	slices.SortFunc(authors, func(a, b *models.AuthorWithBookCount) int {
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return paginate(authors, limit, offset), nil
+}
+
+func (r *authorRepository) CountAll(ctx context.Context) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.authors), nil
+}
+
+func (r *authorRepository) SetBookAuthors(ctx context.Context, bookID string, authorIDs []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[bookID]; !ok {
+		return errForeignKey
+	}
+	for _, authorID := range authorIDs {
+		if _, ok := r.store.authors[authorID]; !ok {
+			return errForeignKey
+		}
+	}
+
+	for key, link := range r.store.bookAuthors {
+		if link.BookID == bookID {
+			delete(r.store.bookAuthors, key)
+		}
+	}
+	for position, authorID := range authorIDs {
+		r.store.bookAuthors[pairKey(bookID, authorID)] = &bookAuthor{
+			BookID:   bookID,
+			AuthorID: authorID,
+			Position: position,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type bookFileRepository struct {
+	store *Store
+}
+
+func NewBookFileRepository(store *Store) repository.BookFileRepository {
+	return &bookFileRepository{store: store}
+}
+
+func (r *bookFileRepository) Create(ctx context.Context, file *models.BookFile) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[file.BookID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[file.UploadedBy]; file.UploadedBy != "" && !ok {
+		return errForeignKey
+	}
+
+	version := 0
+	for _, stored := range r.store.bookFiles {
+		if stored.BookID == file.BookID {
+			version = max(version, stored.Version)
+		}
+	}
+
+	file.ID = uuid.New().String()
+	file.Version = version + 1
+	file.CreatedAt = time.Now()
+
+	stored := *file
+	r.store.bookFiles[file.ID] = &stored
+	return nil
+}
+
+func (r *bookFileRepository) FindByBookID(ctx context.Context, bookID string) ([]*models.BookFile, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var files []*models.BookFile
+	for _, file := range r.store.bookFiles {
+		if file.BookID == bookID {
+			found := *file
+			files = append(files, &found)
+		}
+	}
+
+	slices.SortFunc(files, func(a, b *models.BookFile) int {
+		return cmp.Compare(b.Version, a.Version)
+	})
+	return files, nil
+}
+
+func (r *bookFileRepository) FindByVersion(ctx context.Context, bookID string, version int) (*models.BookFile, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, file := range r.store.bookFiles {
+		if file.BookID == bookID && file.Version == version {
+			found := *file
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
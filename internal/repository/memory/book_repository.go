@@ -0,0 +1,439 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type bookRepository struct {
+	store *Store
+}
+
+func NewBookRepository(store *Store) repository.BookRepository {
+	return &bookRepository{store: store}
+}
+
+func (r *bookRepository) Create(ctx context.Context, book *models.Book) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.categories[book.CategoryID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[book.OwnerID]; !ok {
+		return errForeignKey
+	}
+
+	book.ID = uuid.New().String()
+	book.LikeCount, book.DislikeCount, book.SaveCount, book.DownloadCount = 0, 0, 0, 0
+	book.CreatedAt = time.Now()
+	book.UpdatedAt = book.CreatedAt
+
+	stored := *book
+	r.store.books[book.ID] = &stored
+	return nil
+}
+
+func (r *bookRepository) Update(ctx context.Context, book *models.Book) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.books[book.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if _, ok := r.store.categories[book.CategoryID]; !ok {
+		return errForeignKey
+	}
+
+	stored.Title = book.Title
+	stored.Description = book.Description
+	stored.CategoryID = book.CategoryID
+	stored.UpdatedAt = time.Now()
+
+	book.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *bookRepository) UpdateFileHash(ctx context.Context, id, hash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if book, ok := r.store.books[id]; ok {
+		book.FileHash = hash
+	}
+	return nil
+}
+
+func (r *bookRepository) UpdateFile(ctx context.Context, file *models.BookFile) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if book, ok := r.store.books[file.BookID]; ok {
+		book.PDFFile = file.PDFFile
+		book.FileHash = file.FileHash
+		book.Format = file.Format
+		book.PageCount = file.PageCount
+		book.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *bookRepository) UpdateCover(ctx context.Context, id, cover string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if book, ok := r.store.books[id]; ok {
+		book.CoverImage = cover
+		book.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if book, ok := r.store.books[id]; ok && book.DeletedAt == nil {
+		now := time.Now()
+		book.DeletedAt = &now
+	}
+	return nil
+}
+
+func (r *bookRepository) Restore(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book, ok := r.store.books[id]
+	if !ok || book.DeletedAt == nil {
+		return sql.ErrNoRows
+	}
+	book.DeletedAt = nil
+	return nil
+}
+
+func (r *bookRepository) Purge(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteBook(id)
+	return nil
+}
+
+func (r *bookRepository) FindByID(ctx context.Context, id string) (*models.BookWithCategory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	book, ok := r.store.books[id]
+	if !ok || book.DeletedAt != nil {
+		return nil, nil
+	}
+	joined, ok := r.store.bookWithCategory(book)
+	if !ok {
+		return nil, nil
+	}
+	return joined, nil
+}
+
+func (r *bookRepository) FindDeletedByID(ctx context.Context, id string) (*models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	book, ok := r.store.books[id]
+	if !ok || book.DeletedAt == nil {
+		return nil, nil
+	}
+	found := *book
+	return &found, nil
+}
+
+func (r *bookRepository) FindDeletedOlderThan(ctx context.Context, retention time.Duration) ([]*models.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var books []*models.Book
+	for _, book := range r.store.books {
+		if book.DeletedAt != nil && time.Since(*book.DeletedAt) > retention {
+			found := *book
+			books = append(books, &found)
+		}
+	}
+	slices.SortFunc(books, func(a, b *models.Book) int {
+		return a.DeletedAt.Compare(*b.DeletedAt)
+	})
+	return books, nil
+}
+
+func (r *bookRepository) FindAllFiles(ctx context.Context) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var files []string
+	for _, book := range r.store.books {
+		files = append(files, book.PDFFile)
+	}
+	for _, file := range r.store.bookFiles {
+		files = append(files, file.PDFFile)
+	}
+	return files, nil
+}
+
+func (r *bookRepository) FindAllCovers(ctx context.Context) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var covers []string
+	for _, book := range r.store.books {
+		if book.CoverImage != "" {
+			covers = append(covers, book.CoverImage)
+		}
+	}
+	return covers, nil
+}
+
+// Lock is a no-op: every memory operation already runs under the store lock
+func (r *bookRepository) Lock(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *bookRepository) FindAll(ctx context.Context) ([]*models.BookWithCategory, error) {
+	return r.FindAllPaginated(ctx, 0, 0, repository.BookSort{})
+}
+
+func (r *bookRepository) FindAllPaginated(ctx context.Context, limit, offset int, sort repository.BookSort) ([]*models.BookWithCategory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.findBooks(func(*models.Book) bool { return true })
+	sortBooks(books, sort, bySaveCountDesc)
+	return paginate(books, limit, offset), nil
+}
+
+func (r *bookRepository) FindByCategory(ctx context.Context, categoryID string) ([]*models.BookWithCategory, error) {
+	return r.FindByCategoryPaginated(ctx, categoryID, 0, 0, repository.BookSort{})
+}
+
+func (r *bookRepository) FindByCategoryPaginated(ctx context.Context, categoryID string, limit, offset int, sort repository.BookSort) ([]*models.BookWithCategory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.findBooks(func(book *models.Book) bool { return book.CategoryID == categoryID })
+	sortBooks(books, sort, bySaveCountDesc)
+	return paginate(books, limit, offset), nil
+}
+
+func (r *bookRepository) FindByCategoryTreePaginated(ctx context.Context, categoryID string, limit, offset int, sort repository.BookSort) ([]*models.BookWithCategory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	subtree := r.store.subtree(categoryID)
+	books := r.store.findBooks(func(book *models.Book) bool { return subtree[book.CategoryID] })
+	sortBooks(books, sort, bySaveCountDesc)
+	return paginate(books, limit, offset), nil
+}
+
+func (r *bookRepository) FindByAuthorPaginated(ctx context.Context, authorID string, limit, offset int, sort repository.BookSort) ([]*models.BookWithCategory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := r.store.findBooks(func(book *models.Book) bool {
+		_, ok := r.store.bookAuthors[pairKey(book.ID, authorID)]
+		return ok
+	})
+	sortBooks(books, sort, byCreatedAtDesc)
+	return paginate(books, limit, offset), nil
+}
+
+func (r *bookRepository) UpdateLikeCount(ctx context.Context, bookID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book, ok := r.store.books[bookID]
+	if !ok {
+		return nil
+	}
+
+	book.LikeCount, book.DislikeCount = 0, 0
+	for _, like := range r.store.likes {
+		if like.BookID != bookID {
+			continue
+		}
+		if like.IsLike {
+			book.LikeCount++
+		} else {
+			book.DislikeCount++
+		}
+	}
+	return nil
+}
+
+func (r *bookRepository) UpdateSaveCount(ctx context.Context, bookID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book, ok := r.store.books[bookID]
+	if !ok {
+		return nil
+	}
+
+	book.SaveCount = 0
+	for _, saved := range r.store.savedBooks {
+		if saved.BookID == bookID {
+			book.SaveCount++
+		}
+	}
+	return nil
+}
+
+func (r *bookRepository) CountAll(ctx context.Context) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, book := range r.store.books {
+		if book.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *bookRepository) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, book := range r.store.books {
+		if book.CategoryID == categoryID && book.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *bookRepository) CountDeletedByCategory(ctx context.Context, categoryID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, book := range r.store.books {
+		if book.CategoryID == categoryID && book.DeletedAt != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *bookRepository) CountByCategoryTree(ctx context.Context, categoryID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	subtree := r.store.subtree(categoryID)
+	count := 0
+	for _, book := range r.store.books {
+		if subtree[book.CategoryID] && book.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *bookRepository) CountByAuthor(ctx context.Context, authorID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, link := range r.store.bookAuthors {
+		if link.AuthorID == authorID && r.store.books[link.BookID].DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Search approximates the PostgreSQL full-text search: every search term has
+// to be a prefix of a word in the title, description, free-text author or
+// author names, and relevance is the number of words matched. There is no
+// stemming or stop word handling.
+func (r *bookRepository) Search(ctx context.Context, filter repository.BookSearchFilter) ([]*models.BookWithCategory, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	terms := searchWords(filter.Search)
+	rank := make(map[string]int)
+
+	books := r.store.findBooks(func(book *models.Book) bool {
+		if filter.CategoryID != "" && book.CategoryID != filter.CategoryID {
+			return false
+		}
+		if filter.MinPages > 0 && (book.PageCount == 0 || book.PageCount < filter.MinPages) {
+			return false
+		}
+		if filter.MaxPages > 0 && (book.PageCount == 0 || book.PageCount > filter.MaxPages) {
+			return false
+		}
+		if len(filter.Tags) > 0 {
+			tags := r.store.tagsOf(book.ID)
+			matched := 0
+			for _, tag := range filter.Tags {
+				if slices.Contains(tags, tag) {
+					matched++
+				}
+			}
+			if matched == 0 || filter.MatchAllTags && matched < len(filter.Tags) {
+				return false
+			}
+		}
+		if len(terms) == 0 {
+			return true
+		}
+
+		text := book.Title + " " + book.Description + " " + book.Author
+		for _, author := range r.store.authorsOf(book.ID) {
+			text += " " + author.Name
+		}
+		words := searchWords(text)
+		for _, term := range terms {
+			matched := 0
+			for _, word := range words {
+				if strings.HasPrefix(word, term) {
+					matched++
+				}
+			}
+			if matched == 0 {
+				return false
+			}
+			rank[book.ID] += matched
+		}
+		return true
+	})
+
+	fallback := bySaveCountDesc
+	if len(terms) > 0 {
+		fallback = func(a, b *models.BookWithCategory) int {
+			if c := cmp.Compare(rank[b.ID], rank[a.ID]); c != 0 {
+				return c
+			}
+			return bySaveCountDesc(a, b)
+		}
+	}
+	sortBooks(books, filter.Sort, fallback)
+
+	return paginate(books, filter.Limit, filter.Offset), len(books), nil
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) repository.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+// nameTaken reports whether another category already uses the name. The caller must hold the lock.
+func (r *categoryRepository) nameTaken(name, exceptID string) bool {
+	for _, category := range r.store.categories {
+		if category.ID != exceptID && category.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.nameTaken(category.Name, "") {
+		return repository.ErrConflict
+	}
+	if category.ParentID != nil {
+		if _, ok := r.store.categories[*category.ParentID]; !ok {
+			return errForeignKey
+		}
+	}
+
+	category.ID = uuid.New().String()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+
+	stored := *category
+	r.store.categories[category.ID] = &stored
+	return nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.categories[category.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if r.nameTaken(category.Name, category.ID) {
+		return repository.ErrConflict
+	}
+	if category.ParentID != nil {
+		if _, ok := r.store.categories[*category.ParentID]; !ok {
+			return errForeignKey
+		}
+	}
+
+	stored.Name = category.Name
+	stored.Description = category.Description
+	stored.ParentID = category.ParentID
+	stored.UpdatedAt = time.Now()
+
+	category.CreatedAt = stored.CreatedAt
+	category.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *categoryRepository) FindAll(ctx context.Context) ([]*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var categories []*models.Category
+	for _, category := range r.store.categories {
+		found := *category
+		categories = append(categories, &found)
+	}
+	slices.SortFunc(categories, func(a, b *models.Category) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return categories, nil
+}
+
+func (r *categoryRepository) FindAllWithBookCount(ctx context.Context) ([]*models.CategoryWithBookCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, book := range r.store.books {
+		if book.DeletedAt == nil {
+			counts[book.CategoryID]++
+		}
+	}
+
+	var categories []*models.CategoryWithBookCount
+	for _, category := range r.store.categories {
+		categories = append(categories, &models.CategoryWithBookCount{
+			Category:  *category,
+			BookCount: counts[category.ID],
+		})
+	}
+	slices.SortFunc(categories, func(a, b *models.CategoryWithBookCount) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return categories, nil
+}
+
+// Delete removes the category together with its books. Like the
+// ON DELETE RESTRICT rule on parent_id, categories with children cannot be deleted.
+func (r *categoryRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.categories[id]; !ok {
+		return sql.ErrNoRows
+	}
+	for _, category := range r.store.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			return errForeignKey
+		}
+	}
+
+	delete(r.store.categories, id)
+	for _, book := range r.store.books {
+		if book.CategoryID == id {
+			r.store.deleteBook(book.ID)
+		}
+	}
+	return nil
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, id string) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	category, ok := r.store.categories[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *category
+	return &found, nil
+}
+
+func (r *categoryRepository) FindByName(ctx context.Context, name string) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, category := range r.store.categories {
+		if strings.EqualFold(category.Name, name) {
+			found := *category
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *categoryRepository) CountChildren(ctx context.Context, id string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, category := range r.store.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *categoryRepository) IsInSubtree(ctx context.Context, rootID, candidateID string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.subtree(rootID)[candidateID], nil
+}
@@ -0,0 +1,437 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type commentRepository struct {
+	store *Store
+}
+
+func NewCommentRepository(store *Store) repository.CommentRepository {
+	return &commentRepository{store: store}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[comment.BookID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[comment.UserID]; !ok {
+		return errForeignKey
+	}
+	if comment.ParentID != nil {
+		if _, ok := r.store.comments[*comment.ParentID]; !ok {
+			return errForeignKey
+		}
+	}
+
+	comment.ID = uuid.New().String()
+	comment.LikeCount, comment.DislikeCount = 0, 0
+	comment.DeletedAt = nil
+	comment.CreatedAt = time.Now()
+	comment.UpdatedAt = comment.CreatedAt
+
+	stored := *comment
+	r.store.comments[comment.ID] = &stored
+	return nil
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id string) (*models.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *comment
+	return &found, nil
+}
+
+// Lock is a no-op: every memory operation already runs under the store lock
+func (r *commentRepository) Lock(ctx context.Context, id string) error {
+	return nil
+}
+
+// Update changes the content of a comment created less than editWindow ago and
+// returns sql.ErrNoRows once the window has passed
+func (r *commentRepository) Update(ctx context.Context, comment *models.Comment, editWindow time.Duration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.comments[comment.ID]
+	if !ok || time.Since(stored.CreatedAt) > editWindow {
+		return sql.ErrNoRows
+	}
+
+	stored.Content = comment.Content
+	stored.UpdatedAt = time.Now()
+
+	comment.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteComment(id)
+	return nil
+}
+
+func (r *commentRepository) SoftDelete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if comment, ok := r.store.comments[id]; ok {
+		now := time.Now()
+		comment.Content = models.DeletedCommentContent
+		comment.DeletedAt = &now
+	}
+	return nil
+}
+
+func (r *commentRepository) UpdateLikeCount(ctx context.Context, commentID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	comment, ok := r.store.comments[commentID]
+	if !ok {
+		return nil
+	}
+
+	comment.LikeCount, comment.DislikeCount = 0, 0
+	for _, like := range r.store.commentLikes {
+		if like.CommentID != commentID {
+			continue
+		}
+		if like.IsLike {
+			comment.LikeCount++
+		} else {
+			comment.DislikeCount++
+		}
+	}
+	return nil
+}
+
+func (r *commentRepository) CountReplies(ctx context.Context, id string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, comment := range r.store.comments {
+		if comment.ParentID != nil && *comment.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// findComments returns the comments matching the predicate joined with their
+// authors. The caller must hold the lock.
+func (r *commentRepository) findComments(match func(comment *models.Comment) bool) []*models.CommentWithUser {
+	var comments []*models.CommentWithUser
+	for _, comment := range r.store.comments {
+		if !match(comment) {
+			continue
+		}
+		user, ok := r.store.users[comment.UserID]
+		if !ok {
+			continue
+		}
+		comments = append(comments, &models.CommentWithUser{
+			Comment:       *comment,
+			UserFirstName: user.FirstName,
+			UserLastName:  user.LastName,
+		})
+	}
+	return comments
+}
+
+func (r *commentRepository) FindByBookID(ctx context.Context, bookID string) ([]*models.CommentWithUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comments := r.findComments(func(comment *models.Comment) bool { return comment.BookID == bookID })
+	slices.SortFunc(comments, func(a, b *models.CommentWithUser) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return comments, nil
+}
+
+func (r *commentRepository) FindThreadsByBookID(ctx context.Context, bookID, sort string, limit, offset int) ([]*models.CommentWithUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comments := r.findComments(func(comment *models.Comment) bool {
+		return comment.BookID == bookID && comment.ParentID == nil
+	})
+	sortComments(comments, sort, false)
+	return paginate(comments, limit, offset), nil
+}
+
+func (r *commentRepository) FindReplies(ctx context.Context, parentIDs []string, sort string) ([]*models.CommentWithUser, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ancestors := make(map[string]bool)
+	for _, id := range parentIDs {
+		ancestors[id] = true
+	}
+
+	// Walk down one level at a time until no further replies are found
+	replyIDs := make(map[string]bool)
+	for found := true; found; {
+		found = false
+		for _, comment := range r.store.comments {
+			if comment.ParentID == nil || replyIDs[comment.ID] {
+				continue
+			}
+			if ancestors[*comment.ParentID] || replyIDs[*comment.ParentID] {
+				replyIDs[comment.ID] = true
+				found = true
+			}
+		}
+	}
+
+	comments := r.findComments(func(comment *models.Comment) bool { return replyIDs[comment.ID] })
+	sortComments(comments, sort, true)
+	return comments, nil
+}
+
+func (r *commentRepository) CountThreadsByBookID(ctx context.Context, bookID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, comment := range r.store.comments {
+		if comment.BookID == bookID && comment.ParentID == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *commentRepository) CountByBookID(ctx context.Context, bookID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, comment := range r.store.comments {
+		if comment.BookID == bookID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// sortComments mirrors the comment orderings of the PostgreSQL repository:
+// threads are newest first by default, replies always oldest first unless
+// sort is "top", which orders by score.
+func sortComments(comments []*models.CommentWithUser, sort string, replies bool) {
+	slices.SortFunc(comments, func(a, b *models.CommentWithUser) int {
+		if sort == "top" {
+			if c := cmp.Compare(b.LikeCount-b.DislikeCount, a.LikeCount-a.DislikeCount); c != 0 {
+				return c
+			}
+		}
+
+		c := a.CreatedAt.Compare(b.CreatedAt)
+		if !replies && sort != "oldest" {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+type likeRepository struct {
+	store *Store
+}
+
+func NewLikeRepository(store *Store) repository.LikeRepository {
+	return &likeRepository{store: store}
+}
+
+func (r *likeRepository) Upsert(ctx context.Context, like *models.Like) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[like.BookID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[like.UserID]; !ok {
+		return errForeignKey
+	}
+
+	like.ID = uuid.New().String()
+
+	key := pairKey(like.UserID, like.BookID)
+	if existing, ok := r.store.likes[key]; ok {
+		existing.IsLike = like.IsLike
+		like.CreatedAt = existing.CreatedAt
+		return nil
+	}
+
+	like.CreatedAt = time.Now()
+	stored := *like
+	r.store.likes[key] = &stored
+	return nil
+}
+
+func (r *likeRepository) Delete(ctx context.Context, userID, bookID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.likes, pairKey(userID, bookID))
+	return nil
+}
+
+func (r *likeRepository) FindUserLikes(ctx context.Context, userID string, bookIDs []string) (map[string]bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	likes := make(map[string]bool)
+	for _, bookID := range bookIDs {
+		if like, ok := r.store.likes[pairKey(userID, bookID)]; ok {
+			likes[bookID] = like.IsLike
+		}
+	}
+	return likes, nil
+}
+
+type commentLikeRepository struct {
+	store *Store
+}
+
+func NewCommentLikeRepository(store *Store) repository.CommentLikeRepository {
+	return &commentLikeRepository{store: store}
+}
+
+func (r *commentLikeRepository) Upsert(ctx context.Context, like *models.CommentLike) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.comments[like.CommentID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[like.UserID]; !ok {
+		return errForeignKey
+	}
+
+	like.ID = uuid.New().String()
+
+	key := pairKey(like.UserID, like.CommentID)
+	if existing, ok := r.store.commentLikes[key]; ok {
+		existing.IsLike = like.IsLike
+		like.CreatedAt = existing.CreatedAt
+		return nil
+	}
+
+	like.CreatedAt = time.Now()
+	stored := *like
+	r.store.commentLikes[key] = &stored
+	return nil
+}
+
+func (r *commentLikeRepository) Delete(ctx context.Context, userID, commentID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.commentLikes, pairKey(userID, commentID))
+	return nil
+}
+
+type savedBookRepository struct {
+	store *Store
+}
+
+func NewSavedBookRepository(store *Store) repository.SavedBookRepository {
+	return &savedBookRepository{store: store}
+}
+
+// Create saves a book for the user. Like the ON CONFLICT DO NOTHING insert it
+// replaces, saving a book twice returns sql.ErrNoRows.
+func (r *savedBookRepository) Create(ctx context.Context, saved *models.SavedBook) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[saved.BookID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[saved.UserID]; !ok {
+		return errForeignKey
+	}
+
+	key := pairKey(saved.UserID, saved.BookID)
+	if _, ok := r.store.savedBooks[key]; ok {
+		return sql.ErrNoRows
+	}
+
+	saved.ID = uuid.New().String()
+	saved.CreatedAt = time.Now()
+
+	stored := *saved
+	r.store.savedBooks[key] = &stored
+	return nil
+}
+
+func (r *savedBookRepository) Delete(ctx context.Context, userID, bookID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.savedBooks, pairKey(userID, bookID))
+	return nil
+}
+
+func (r *savedBookRepository) FindSavedBookIDs(ctx context.Context, userID string, bookIDs []string) (map[string]bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	saved := make(map[string]bool)
+	for _, bookID := range bookIDs {
+		if _, ok := r.store.savedBooks[pairKey(userID, bookID)]; ok {
+			saved[bookID] = true
+		}
+	}
+	return saved, nil
+}
+
+func (r *savedBookRepository) FindByUserID(ctx context.Context, userID string, sort repository.BookSort) ([]*models.BookWithCategory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	savedAt := make(map[string]time.Time)
+	for _, saved := range r.store.savedBooks {
+		if saved.UserID == userID {
+			savedAt[saved.BookID] = saved.CreatedAt
+		}
+	}
+
+	books := r.store.findBooks(func(book *models.Book) bool {
+		_, ok := savedAt[book.ID]
+		return ok
+	})
+	sortBooks(books, sort, func(a, b *models.BookWithCategory) int {
+		return savedAt[b.ID].Compare(savedAt[a.ID])
+	})
+	return books, nil
+}
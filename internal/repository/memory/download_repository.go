@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type downloadRepository struct {
+	store *Store
+}
+
+func NewDownloadRepository(store *Store) repository.DownloadRepository {
+	return &downloadRepository{store: store}
+}
+
+// Create records a download and, like the database trigger, bumps the
+// download counter of the book
+func (r *downloadRepository) Create(ctx context.Context, download *models.Download) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book, ok := r.store.books[download.BookID]
+	if !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[download.UserID]; !ok {
+		return errForeignKey
+	}
+
+	download.ID = uuid.New().String()
+	download.DownloadedAt = time.Now()
+
+	stored := *download
+	r.store.downloads[download.ID] = &stored
+	book.DownloadCount++
+	return nil
+}
+
+func (r *downloadRepository) FindByBookID(ctx context.Context, bookID string, limit, offset int) ([]*models.DownloadWithUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var downloads []*models.DownloadWithUser
+	for _, download := range r.store.downloads {
+		if download.BookID != bookID {
+			continue
+		}
+		user, ok := r.store.users[download.UserID]
+		if !ok {
+			continue
+		}
+		downloads = append(downloads, &models.DownloadWithUser{
+			Download:      *download,
+			UserEmail:     user.Email,
+			UserFirstName: user.FirstName,
+			UserLastName:  user.LastName,
+		})
+	}
+
+	slices.SortFunc(downloads, func(a, b *models.DownloadWithUser) int {
+		if c := b.DownloadedAt.Compare(a.DownloadedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return paginate(downloads, limit, offset), nil
+}
+
+func (r *downloadRepository) CountByBookID(ctx context.Context, bookID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, download := range r.store.downloads {
+		if download.BookID == bookID {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type readingRepository struct {
+	store *Store
+}
+
+func NewReadingRepository(store *Store) repository.ReadingRepository {
+	return &readingRepository{store: store}
+}
+
+func (r *readingRepository) CreateView(ctx context.Context, view *models.BookView) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[view.BookID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[view.UserID]; !ok {
+		return errForeignKey
+	}
+
+	view.ID = uuid.New().String()
+	view.ViewedAt = time.Now()
+
+	stored := *view
+	r.store.views[view.ID] = &stored
+	return nil
+}
+
+func (r *readingRepository) UpsertProgress(ctx context.Context, progress *models.ReadingProgress) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[progress.BookID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.users[progress.UserID]; !ok {
+		return errForeignKey
+	}
+
+	progress.UpdatedAt = time.Now()
+
+	stored := *progress
+	r.store.progress[pairKey(progress.UserID, progress.BookID)] = &stored
+	return nil
+}
+
+func (r *readingRepository) FindProgress(ctx context.Context, userID, bookID string) (*models.ReadingProgress, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	progress, ok := r.store.progress[pairKey(userID, bookID)]
+	if !ok {
+		return nil, nil
+	}
+	found := *progress
+	return &found, nil
+}
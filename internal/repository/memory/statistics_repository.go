@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"time"
+)
+
+type statisticsRepository struct {
+	store *Store
+}
+
+func NewStatisticsRepository(store *Store) repository.StatisticsRepository {
+	return &statisticsRepository{store: store}
+}
+
+func (r *statisticsRepository) GetTotals(ctx context.Context) (*models.LibraryTotals, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	totals := &models.LibraryTotals{Categories: len(r.store.categories)}
+	for _, book := range r.store.books {
+		if book.DeletedAt == nil {
+			totals.Books++
+		}
+	}
+	for _, saved := range r.store.savedBooks {
+		if r.store.books[saved.BookID].DeletedAt == nil {
+			totals.Saves++
+		}
+	}
+	for _, comment := range r.store.comments {
+		if r.store.books[comment.BookID].DeletedAt == nil {
+			totals.Comments++
+		}
+	}
+	return totals, nil
+}
+
+func (r *statisticsRepository) CountCommentsByBook(ctx context.Context, bookID string) (int, error) {
+	return NewCommentRepository(r.store).CountByBookID(ctx, bookID)
+}
+
+func (r *statisticsRepository) CountViewsByBook(ctx context.Context, bookID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, view := range r.store.views {
+		if view.BookID == bookID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetBookActivity buckets the activity on a book by day or ISO week (starting
+// Monday, as date_trunc does) over the given number of days, without gaps
+func (r *statisticsRepository) GetBookActivity(ctx context.Context, bookID, interval string, days int) ([]*models.ActivityBucket, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	step := 1
+	if interval == "week" {
+		step = 7
+	}
+
+	var buckets []*models.ActivityBucket
+	index := make(map[time.Time]*models.ActivityBucket)
+	now := time.Now()
+	end := truncatePeriod(now, interval)
+	for period := truncatePeriod(now.AddDate(0, 0, -days+1), interval); !period.After(end); period = period.AddDate(0, 0, step) {
+		bucket := &models.ActivityBucket{Period: period}
+		buckets = append(buckets, bucket)
+		index[period] = bucket
+	}
+
+	bucketFor := func(at time.Time) *models.ActivityBucket {
+		return index[truncatePeriod(at, interval)]
+	}
+
+	for _, view := range r.store.views {
+		if bucket := bucketFor(view.ViewedAt); view.BookID == bookID && bucket != nil {
+			bucket.Views++
+		}
+	}
+	for _, download := range r.store.downloads {
+		if bucket := bucketFor(download.DownloadedAt); download.BookID == bookID && bucket != nil {
+			bucket.Downloads++
+		}
+	}
+	for _, saved := range r.store.savedBooks {
+		if bucket := bucketFor(saved.CreatedAt); saved.BookID == bookID && bucket != nil {
+			bucket.Saves++
+		}
+	}
+	for _, like := range r.store.likes {
+		bucket := bucketFor(like.CreatedAt)
+		if like.BookID != bookID || bucket == nil {
+			continue
+		}
+		if like.IsLike {
+			bucket.Likes++
+		} else {
+			bucket.Dislikes++
+		}
+	}
+	for _, comment := range r.store.comments {
+		if bucket := bucketFor(comment.CreatedAt); comment.BookID == bookID && bucket != nil {
+			bucket.Comments++
+		}
+	}
+
+	return buckets, nil
+}
+
+func truncatePeriod(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	if interval != "week" {
+		return day
+	}
+	// time.Weekday counts from Sunday, ISO weeks start on Monday
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
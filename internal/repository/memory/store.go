@@ -0,0 +1,295 @@
+// Package memory implements the repository interfaces on top of in-memory
+// maps. It mirrors the behaviour of the PostgreSQL repositories closely
+// enough for service level tests, including the counters maintained by
+// triggers and the ON DELETE CASCADE rules of the schema.
+//
+// All repositories created from the same Store share its data and are safe
+// for concurrent use. Operations never block, so the contexts passed in are
+// accepted for interface compatibility but not consulted.
+package memory
+
+import (
+	"cmp"
+	"errors"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// errForeignKey is returned where PostgreSQL would report a foreign key violation
+var errForeignKey = errors.New("memory: foreign key violation")
+
+type Store struct {
+	mu sync.RWMutex
+	// txMu serializes units of work
+	txMu sync.Mutex
+
+	users         map[string]*models.User
+	refreshTokens map[string]*models.RefreshToken
+	categories    map[string]*models.Category
+	authors       map[string]*models.Author
+	books         map[string]*models.Book
+	bookAuthors   map[string]*bookAuthor
+	tags          map[string]*models.Tag
+	bookTags      map[string]*bookTag
+	bookFiles     map[string]*models.BookFile
+	comments      map[string]*models.Comment
+	likes         map[string]*models.Like
+	commentLikes  map[string]*models.CommentLike
+	savedBooks    map[string]*models.SavedBook
+	downloads     map[string]*models.Download
+	views         map[string]*models.BookView
+	progress      map[string]*models.ReadingProgress
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:         make(map[string]*models.User),
+		refreshTokens: make(map[string]*models.RefreshToken),
+		categories:    make(map[string]*models.Category),
+		authors:       make(map[string]*models.Author),
+		books:         make(map[string]*models.Book),
+		bookAuthors:   make(map[string]*bookAuthor),
+		tags:          make(map[string]*models.Tag),
+		bookTags:      make(map[string]*bookTag),
+		bookFiles:     make(map[string]*models.BookFile),
+		comments:      make(map[string]*models.Comment),
+		likes:         make(map[string]*models.Like),
+		commentLikes:  make(map[string]*models.CommentLike),
+		savedBooks:    make(map[string]*models.SavedBook),
+		downloads:     make(map[string]*models.Download),
+		views:         make(map[string]*models.BookView),
+		progress:      make(map[string]*models.ReadingProgress),
+	}
+}
+
+// bookAuthor is a row of the book_authors join table
+type bookAuthor struct {
+	BookID   string
+	AuthorID string
+	Position int
+}
+
+// bookTag is a row of the book_tags join table
+type bookTag struct {
+	BookID string
+	TagID  string
+}
+
+// pairKey builds the key of tables with a (user_id, other_id) unique constraint
+func pairKey(userID, otherID string) string {
+	return userID + "/" + otherID
+}
+
+// bookWithCategory joins a book with its category. The caller must hold the lock.
+func (s *Store) bookWithCategory(book *models.Book) (*models.BookWithCategory, bool) {
+	category, ok := s.categories[book.CategoryID]
+	if !ok {
+		return nil, false
+	}
+	return &models.BookWithCategory{
+		Book:         *book,
+		CategoryName: category.Name,
+		Authors:      s.authorsOf(book.ID),
+		Tags:         s.tagsOf(book.ID),
+	}, true
+}
+
+// authorsOf returns the authors of a book in order of appearance. The caller must hold the lock.
+func (s *Store) authorsOf(bookID string) []models.BookAuthor {
+	var links []*bookAuthor
+	for _, link := range s.bookAuthors {
+		if link.BookID == bookID {
+			links = append(links, link)
+		}
+	}
+	slices.SortFunc(links, func(a, b *bookAuthor) int { return cmp.Compare(a.Position, b.Position) })
+
+	authors := make([]models.BookAuthor, 0, len(links))
+	for _, link := range links {
+		author := s.authors[link.AuthorID]
+		authors = append(authors, models.BookAuthor{ID: author.ID, Name: author.Name})
+	}
+	return authors
+}
+
+// tagsOf returns the tag names of a book sorted by name. The caller must hold the lock.
+func (s *Store) tagsOf(bookID string) []string {
+	tags := []string{}
+	for _, link := range s.bookTags {
+		if link.BookID == bookID {
+			tags = append(tags, s.tags[link.TagID].Name)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// findBooks returns the joined books matching the predicate, leaving out soft
+// deleted books. The caller must hold the lock.
+func (s *Store) findBooks(match func(book *models.Book) bool) []*models.BookWithCategory {
+	var books []*models.BookWithCategory
+	for _, book := range s.books {
+		if book.DeletedAt != nil || !match(book) {
+			continue
+		}
+		if joined, ok := s.bookWithCategory(book); ok {
+			books = append(books, joined)
+		}
+	}
+	return books
+}
+
+// subtree returns the IDs of the category and all its descendants. The caller must hold the lock.
+func (s *Store) subtree(categoryID string) map[string]bool {
+	ids := make(map[string]bool)
+	if _, ok := s.categories[categoryID]; !ok {
+		return ids
+	}
+
+	queue := []string{categoryID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		ids[id] = true
+		for _, category := range s.categories {
+			if category.ParentID != nil && *category.ParentID == id && !ids[category.ID] {
+				queue = append(queue, category.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// deleteBook removes a book and everything that references it. The caller must hold the lock.
+func (s *Store) deleteBook(id string) {
+	delete(s.books, id)
+	for key, link := range s.bookAuthors {
+		if link.BookID == id {
+			delete(s.bookAuthors, key)
+		}
+	}
+	for key, link := range s.bookTags {
+		if link.BookID == id {
+			delete(s.bookTags, key)
+		}
+	}
+	for key, file := range s.bookFiles {
+		if file.BookID == id {
+			delete(s.bookFiles, key)
+		}
+	}
+	for key, like := range s.likes {
+		if like.BookID == id {
+			delete(s.likes, key)
+		}
+	}
+	for key, saved := range s.savedBooks {
+		if saved.BookID == id {
+			delete(s.savedBooks, key)
+		}
+	}
+	for key, download := range s.downloads {
+		if download.BookID == id {
+			delete(s.downloads, key)
+		}
+	}
+	for key, view := range s.views {
+		if view.BookID == id {
+			delete(s.views, key)
+		}
+	}
+	for key, progress := range s.progress {
+		if progress.BookID == id {
+			delete(s.progress, key)
+		}
+	}
+	for _, comment := range s.comments {
+		if comment.BookID == id {
+			s.deleteComment(comment.ID)
+		}
+	}
+}
+
+// deleteComment removes a comment, its replies and their likes. The caller must hold the lock.
+func (s *Store) deleteComment(id string) {
+	if _, ok := s.comments[id]; !ok {
+		return
+	}
+	delete(s.comments, id)
+	for key, like := range s.commentLikes {
+		if like.CommentID == id {
+			delete(s.commentLikes, key)
+		}
+	}
+	for _, comment := range s.comments {
+		if comment.ParentID != nil && *comment.ParentID == id {
+			s.deleteComment(comment.ID)
+		}
+	}
+}
+
+// bookComparators mirrors the sortable fields of the PostgreSQL book repository
+var bookComparators = map[string]func(a, b *models.BookWithCategory) int{
+	"created_at": func(a, b *models.BookWithCategory) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *models.BookWithCategory) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"save_count": func(a, b *models.BookWithCategory) int { return cmp.Compare(a.SaveCount, b.SaveCount) },
+	"title": func(a, b *models.BookWithCategory) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	},
+	"like_count":     func(a, b *models.BookWithCategory) int { return cmp.Compare(a.LikeCount, b.LikeCount) },
+	"download_count": func(a, b *models.BookWithCategory) int { return cmp.Compare(a.DownloadCount, b.DownloadCount) },
+	"page_count":     func(a, b *models.BookWithCategory) int { return cmp.Compare(a.PageCount, b.PageCount) },
+	"rating":         func(a, b *models.BookWithCategory) int { return cmp.Compare(rating(a), rating(b)) },
+}
+
+func rating(book *models.BookWithCategory) float64 {
+	return (float64(book.LikeCount) + 1) / (float64(book.LikeCount+book.DislikeCount) + 2)
+}
+
+// bySaveCountDesc is the default book ordering
+func bySaveCountDesc(a, b *models.BookWithCategory) int {
+	return cmp.Compare(b.SaveCount, a.SaveCount)
+}
+
+func byCreatedAtDesc(a, b *models.BookWithCategory) int {
+	return b.CreatedAt.Compare(a.CreatedAt)
+}
+
+// sortBooks orders books like BookSort does in SQL, using fallback when no
+// (or an unknown) field is requested and the book ID as tie-breaker
+func sortBooks(books []*models.BookWithCategory, sort repository.BookSort, fallback func(a, b *models.BookWithCategory) int) {
+	compare := fallback
+	if field, ok := bookComparators[sort.Field]; ok {
+		direction := -1
+		switch strings.ToLower(sort.Order) {
+		case "asc":
+			direction = 1
+		case "":
+			if sort.Field == "title" {
+				direction = 1
+			}
+		}
+		compare = func(a, b *models.BookWithCategory) int { return direction * field(a, b) }
+	}
+
+	slices.SortFunc(books, func(a, b *models.BookWithCategory) int {
+		if c := compare(a, b); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// paginate applies LIMIT/OFFSET semantics; a limit of zero returns everything
+func paginate[T any](items []T, limit, offset int) []T {
+	if limit <= 0 {
+		return items
+	}
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
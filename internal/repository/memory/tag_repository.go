@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type tagRepository struct {
+	store *Store
+}
+
+func NewTagRepository(store *Store) repository.TagRepository {
+	return &tagRepository{store: store}
+}
+
+func (r *tagRepository) SetBookTags(ctx context.Context, bookID string, names []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[bookID]; !ok {
+		return errForeignKey
+	}
+
+	for key, link := range r.store.bookTags {
+		if link.BookID == bookID {
+			delete(r.store.bookTags, key)
+		}
+	}
+	for _, name := range names {
+		tag := r.findByName(name)
+		if tag == nil {
+			tag = &models.Tag{ID: uuid.New().String(), Name: name, CreatedAt: time.Now()}
+			r.store.tags[tag.ID] = tag
+		}
+		r.store.bookTags[pairKey(bookID, tag.ID)] = &bookTag{BookID: bookID, TagID: tag.ID}
+	}
+	return nil
+}
+
+// findByName returns the tag with the name or nil. The caller must hold the lock.
+func (r *tagRepository) findByName(name string) *models.Tag {
+	for _, tag := range r.store.tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+func (r *tagRepository) FindByPrefix(ctx context.Context, prefix string, limit int) ([]*models.TagWithBookCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, link := range r.store.bookTags {
+		if r.store.books[link.BookID].DeletedAt == nil {
+			counts[link.TagID]++
+		}
+	}
+
+	var tags []*models.TagWithBookCount
+	for _, tag := range r.store.tags {
+		if counts[tag.ID] > 0 && strings.HasPrefix(tag.Name, prefix) {
+			tags = append(tags, &models.TagWithBookCount{Tag: *tag, BookCount: counts[tag.ID]})
+		}
+	}
+	slices.SortFunc(tags, func(a, b *models.TagWithBookCount) int {
+		if c := cmp.Compare(b.BookCount, a.BookCount); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return paginate(tags, limit, 0), nil
+}
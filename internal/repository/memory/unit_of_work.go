@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"library-project/internal/repository"
+	"maps"
+)
+
+type unitOfWork struct {
+	store *Store
+}
+
+// NewUnitOfWork returns a unit of work over the store. Units of work run one
+// at a time; when fn fails the store is restored to the state it had before
+// fn was called.
+//
+// The rollback restores the whole store, not just the rows fn wrote, so it
+// also discards anything written meanwhile outside a unit of work. Tests
+// using the store must not write through plain repositories while a unit
+// of work may be running.
+func NewUnitOfWork(store *Store) repository.UnitOfWork {
+	return &unitOfWork{store: store}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	u.store.txMu.Lock()
+	defer u.store.txMu.Unlock()
+
+	snapshot := u.store.snapshot()
+
+	err := fn(&repository.Repositories{
+		Books:        NewBookRepository(u.store),
+		BookFiles:    NewBookFileRepository(u.store),
+		Categories:   NewCategoryRepository(u.store),
+		Authors:      NewAuthorRepository(u.store),
+		Tags:         NewTagRepository(u.store),
+		Comments:     NewCommentRepository(u.store),
+		Likes:        NewLikeRepository(u.store),
+		CommentLikes: NewCommentLikeRepository(u.store),
+		SavedBooks:   NewSavedBookRepository(u.store),
+		Downloads:    NewDownloadRepository(u.store),
+	})
+	if err != nil {
+		u.store.restore(snapshot)
+	}
+
+	return err
+}
+
+// snapshot copies the stored rows so they can be restored after a rollback
+func (s *Store) snapshot() *Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &Store{
+		users:         cloneRows(s.users),
+		refreshTokens: cloneRows(s.refreshTokens),
+		categories:    cloneRows(s.categories),
+		authors:       cloneRows(s.authors),
+		books:         cloneRows(s.books),
+		bookAuthors:   cloneRows(s.bookAuthors),
+		tags:          cloneRows(s.tags),
+		bookTags:      cloneRows(s.bookTags),
+		bookFiles:     cloneRows(s.bookFiles),
+		comments:      cloneRows(s.comments),
+		likes:         cloneRows(s.likes),
+		commentLikes:  cloneRows(s.commentLikes),
+		savedBooks:    cloneRows(s.savedBooks),
+		downloads:     cloneRows(s.downloads),
+		views:         cloneRows(s.views),
+		progress:      cloneRows(s.progress),
+	}
+}
+
+func (s *Store) restore(snapshot *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = snapshot.users
+	s.refreshTokens = snapshot.refreshTokens
+	s.categories = snapshot.categories
+	s.authors = snapshot.authors
+	s.books = snapshot.books
+	s.bookAuthors = snapshot.bookAuthors
+	s.tags = snapshot.tags
+	s.bookTags = snapshot.bookTags
+	s.bookFiles = snapshot.bookFiles
+	s.comments = snapshot.comments
+	s.likes = snapshot.likes
+	s.commentLikes = snapshot.commentLikes
+	s.savedBooks = snapshot.savedBooks
+	s.downloads = snapshot.downloads
+	s.views = snapshot.views
+	s.progress = snapshot.progress
+}
+
+func cloneRows[T any](rows map[string]*T) map[string]*T {
+	cloned := maps.Clone(rows)
+	for key, row := range cloned {
+		copied := *row
+		cloned[key] = &copied
+	}
+	return cloned
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"library-project/internal/models"
+	"library-project/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.users {
+		if existing.Email == user.Email {
+			return repository.ErrConflict
+		}
+	}
+
+	user.ID = uuid.New().String()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	stored := *user
+	r.store.users[user.ID] = &stored
+	return nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *userRepository) InvalidateTokens(ctx context.Context, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[userID]; ok {
+		now := time.Now()
+		user.TokenInvalidatedAt = &now
+	}
+	return nil
+}
+
+type refreshTokenRepository struct {
+	store *Store
+}
+
+func NewRefreshTokenRepository(store *Store) repository.RefreshTokenRepository {
+	return &refreshTokenRepository{store: store}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, rt *models.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[rt.UserID]; !ok {
+		return errForeignKey
+	}
+	if _, ok := r.store.refreshTokens[rt.Token]; ok {
+		return repository.ErrConflict
+	}
+
+	rt.ID = uuid.New().String()
+	rt.CreatedAt = time.Now()
+
+	stored := *rt
+	r.store.refreshTokens[rt.Token] = &stored
+	return nil
+}
+
+func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string) (*models.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rt, ok := r.store.refreshTokens[token]
+	if !ok {
+		return nil, nil
+	}
+	found := *rt
+	return &found, nil
+}
+
+func (r *refreshTokenRepository) DeleteByToken(ctx context.Context, token string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.refreshTokens, token)
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for token, rt := range r.store.refreshTokens {
+		if rt.UserID == userID {
+			delete(r.store.refreshTokens, token)
+		}
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for token, rt := range r.store.refreshTokens {
+		if rt.ExpiresAt.Before(now) {
+			delete(r.store.refreshTokens, token)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type readingRepository struct {
+	db DBTX
+}
+
+func NewReadingRepository(db *sql.DB) ReadingRepository {
+	return &readingRepository{db: db}
+}
+
+func (r *readingRepository) CreateView(ctx context.Context, view *models.BookView) error {
+	view.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO book_views (id, book_id, user_id, ip_address, user_agent)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING viewed_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, view.ID, view.BookID, view.UserID,
+		view.IPAddress, view.UserAgent).Scan(&view.ViewedAt)
+}
+
+func (r *readingRepository) UpsertProgress(ctx context.Context, progress *models.ReadingProgress) error {
+	query := `
+		INSERT INTO reading_progress (user_id, book_id, page)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, book_id)
+		DO UPDATE SET page = EXCLUDED.page, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, progress.UserID, progress.BookID, progress.Page).Scan(&progress.UpdatedAt)
+}
+
+func (r *readingRepository) FindProgress(ctx context.Context, userID, bookID string) (*models.ReadingProgress, error) {
+	progress := &models.ReadingProgress{}
+	query := `
+		SELECT user_id, book_id, page, updated_at
+		FROM reading_progress
+		WHERE user_id = $1 AND book_id = $2
+	`
+
+	err := r.db.QueryRowContext(ctx, query, userID, bookID).Scan(
+		&progress.UserID, &progress.BookID, &progress.Page, &progress.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"library-project/internal/models"
 	"time"
@@ -8,15 +9,15 @@ import (
 	"github.com/google/uuid"
 )
 
-type RefreshTokenRepository struct {
-	db *sql.DB
+type refreshTokenRepository struct {
+	db DBTX
 }
 
-func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
-	return &RefreshTokenRepository{db: db}
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
 }
 
-func (r *RefreshTokenRepository) Create(rt *models.RefreshToken) error {
+func (r *refreshTokenRepository) Create(ctx context.Context, rt *models.RefreshToken) error {
 	rt.ID = uuid.New().String()
 
 	query := `
@@ -25,16 +26,16 @@ func (r *RefreshTokenRepository) Create(rt *models.RefreshToken) error {
 		RETURNING created_at
 	`
 
-	return r.db.QueryRow(query, rt.ID, rt.UserID, rt.Token, rt.ExpiresAt).
+	return r.db.QueryRowContext(ctx, query, rt.ID, rt.UserID, rt.Token, rt.ExpiresAt).
 		Scan(&rt.CreatedAt)
 }
 
-func (r *RefreshTokenRepository) FindByToken(token string) (*models.RefreshToken, error) {
+func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string) (*models.RefreshToken, error) {
 	rt := &models.RefreshToken{}
 
 	query := `SELECT id, user_id, token, expires_at, created_at FROM refresh_tokens WHERE token = $1`
 
-	err := r.db.QueryRow(query, token).Scan(
+	err := r.db.QueryRowContext(ctx, query, token).Scan(
 		&rt.ID, &rt.UserID, &rt.Token, &rt.ExpiresAt, &rt.CreatedAt,
 	)
 
@@ -45,20 +46,20 @@ func (r *RefreshTokenRepository) FindByToken(token string) (*models.RefreshToken
 	return rt, err
 }
 
-func (r *RefreshTokenRepository) DeleteByToken(token string) error {
+func (r *refreshTokenRepository) DeleteByToken(ctx context.Context, token string) error {
 	query := `DELETE FROM refresh_tokens WHERE token = $1`
-	_, err := r.db.Exec(query, token)
+	_, err := r.db.ExecContext(ctx, query, token)
 	return err
 }
 
-func (r *RefreshTokenRepository) DeleteByUserID(userID string) error {
+func (r *refreshTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
 	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID)
 	return err
 }
 
-func (r *RefreshTokenRepository) DeleteExpired() error {
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
 	query := `DELETE FROM refresh_tokens WHERE expires_at < $1`
-	_, err := r.db.Exec(query, time.Now())
+	_, err := r.db.ExecContext(ctx, query, time.Now())
 	return err
 }
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+)
+
+type statisticsRepository struct {
+	db DBTX
+}
+
+func NewStatisticsRepository(db *sql.DB) StatisticsRepository {
+	return &statisticsRepository{db: db}
+}
+
+// GetTotals returns library wide counters in a single round trip. Books in
+// the trash and their saves and comments are not counted.
+func (r *statisticsRepository) GetTotals(ctx context.Context) (*models.LibraryTotals, error) {
+	totals := &models.LibraryTotals{}
+
+	query := `
+		SELECT (SELECT COUNT(*) FROM books WHERE deleted_at IS NULL),
+		       (SELECT COUNT(*) FROM categories),
+		       (SELECT COUNT(*) FROM saved_books s
+		        JOIN books b ON b.id = s.book_id
+		        WHERE b.deleted_at IS NULL),
+		       (SELECT COUNT(*) FROM comments c
+		        JOIN books b ON b.id = c.book_id
+		        WHERE b.deleted_at IS NULL)
+	`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&totals.Books, &totals.Categories, &totals.Saves, &totals.Comments,
+	)
+
+	return totals, err
+}
+
+// CountCommentsByBook returns the number of comments on a book
+func (r *statisticsRepository) CountCommentsByBook(ctx context.Context, bookID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comments WHERE book_id = $1`
+	err := r.db.QueryRowContext(ctx, query, bookID).Scan(&count)
+	return count, err
+}
+
+// CountViewsByBook returns the number of times a book was opened in the web reader
+func (r *statisticsRepository) CountViewsByBook(ctx context.Context, bookID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM book_views WHERE book_id = $1`
+	err := r.db.QueryRowContext(ctx, query, bookID).Scan(&count)
+	return count, err
+}
+
+// GetBookActivity returns the activity on a book grouped into day or week
+// buckets over the given number of days up to now. Periods without activity are
+// included with zero counts so the timeline has no gaps. Both ends of the
+// range come from the database clock the events were recorded with.
+func (r *statisticsRepository) GetBookActivity(ctx context.Context, bookID, interval string, days int) ([]*models.ActivityBucket, error) {
+	query := `
+		WITH events AS (
+			SELECT viewed_at AS occurred_at, 'view' AS kind
+			FROM book_views WHERE book_id = $1
+			UNION ALL
+			SELECT downloaded_at, 'download'
+			FROM downloads WHERE book_id = $1
+			UNION ALL
+			SELECT created_at, 'save'
+			FROM saved_books WHERE book_id = $1
+			UNION ALL
+			SELECT created_at, CASE WHEN is_like THEN 'like' ELSE 'dislike' END
+			FROM likes WHERE book_id = $1
+			UNION ALL
+			SELECT created_at, 'comment'
+			FROM comments WHERE book_id = $1
+		)
+		SELECT p.period,
+		       COUNT(e.kind) FILTER (WHERE e.kind = 'view'),
+		       COUNT(e.kind) FILTER (WHERE e.kind = 'download'),
+		       COUNT(e.kind) FILTER (WHERE e.kind = 'save'),
+		       COUNT(e.kind) FILTER (WHERE e.kind = 'like'),
+		       COUNT(e.kind) FILTER (WHERE e.kind = 'dislike'),
+		       COUNT(e.kind) FILTER (WHERE e.kind = 'comment')
+		FROM generate_series(
+			date_trunc($2, LOCALTIMESTAMP - make_interval(days => $3::int - 1)),
+			date_trunc($2, LOCALTIMESTAMP),
+			('1 ' || $2)::interval
+		) AS p(period)
+		LEFT JOIN events e ON date_trunc($2, e.occurred_at) = p.period
+		GROUP BY p.period
+		ORDER BY p.period
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bookID, interval, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*models.ActivityBucket
+	for rows.Next() {
+		bucket := &models.ActivityBucket{}
+		err := rows.Scan(
+			&bucket.Period, &bucket.Views, &bucket.Downloads, &bucket.Saves,
+			&bucket.Likes, &bucket.Dislikes, &bucket.Comments,
+		)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
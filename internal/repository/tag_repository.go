@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"library-project/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type tagRepository struct {
+	db DBTX
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) SetBookTags(ctx context.Context, bookID string, names []string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM book_tags WHERE book_id = $1`, bookID); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// Create the tags used for the first time, then link all of them
+	query := `INSERT INTO tags (id, name) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`
+	for _, name := range names {
+		if _, err := r.db.ExecContext(ctx, query, uuid.New().String(), name); err != nil {
+			return err
+		}
+	}
+
+	query = `
+        INSERT INTO book_tags (book_id, tag_id)
+        SELECT $1, id FROM tags WHERE name = ANY($2)
+    `
+	_, err := r.db.ExecContext(ctx, query, bookID, pq.Array(names))
+	return err
+}
+
+func (r *tagRepository) FindByPrefix(ctx context.Context, prefix string, limit int) ([]*models.TagWithBookCount, error) {
+	query := `
+        SELECT t.id, t.name, t.created_at, COUNT(*)
+        FROM tags t
+        JOIN book_tags bt ON bt.tag_id = t.id
+        JOIN books b ON b.id = bt.book_id AND b.deleted_at IS NULL
+        WHERE t.name LIKE $1 || '%'
+        GROUP BY t.id
+        ORDER BY COUNT(*) DESC, t.name
+        LIMIT $2
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, escapeLike(prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*models.TagWithBookCount
+	for rows.Next() {
+		tag := &models.TagWithBookCount{}
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.BookCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in user input so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
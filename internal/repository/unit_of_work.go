@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DBTX is the part of *sql.DB and *sql.Tx the repositories use, so the same
+// repository code runs both on its own and inside a transaction
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type unitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos *Repositories) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back after a successful commit is a no-op
+	defer tx.Rollback()
+
+	if err := fn(newRepositories(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func newRepositories(db DBTX) *Repositories {
+	return &Repositories{
+		Books:        &bookRepository{db: db},
+		BookFiles:    &bookFileRepository{db: db},
+		Categories:   &categoryRepository{db: db},
+		Authors:      &authorRepository{db: db},
+		Tags:         &tagRepository{db: db},
+		Comments:     &commentRepository{db: db},
+		Likes:        &likeRepository{db: db},
+		CommentLikes: &commentLikeRepository{db: db},
+		SavedBooks:   &savedBookRepository{db: db},
+		Downloads:    &downloadRepository{db: db},
+	}
+}
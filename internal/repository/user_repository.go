@@ -1,6 +1,7 @@
 package repository
 
 import (
+    "context"
     "database/sql"
     "library-project/internal/models"
     "time"
@@ -8,15 +9,15 @@ import (
     "github.com/google/uuid"
 )
 
-type UserRepository struct {
-    db *sql.DB
+type userRepository struct {
+    db DBTX
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
-    return &UserRepository{db: db}
+func NewUserRepository(db *sql.DB) UserRepository {
+    return &userRepository{db: db}
 }
 
-func (r *UserRepository) Create(user *models.User) error {
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
     user.ID = uuid.New().String()
 
     query := `
@@ -25,12 +26,12 @@ func (r *UserRepository) Create(user *models.User) error {
         RETURNING created_at, updated_at
     `
 
-    return r.db.QueryRow(query, user.ID, user.Email, user.Password,
+    return r.db.QueryRowContext(ctx, query, user.ID, user.Email, user.Password,
         user.FirstName, user.LastName, user.Role).
         Scan(&user.CreatedAt, &user.UpdatedAt)
 }
 
-func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
     user := &models.User{}
 
     query := `
@@ -38,7 +39,7 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
         FROM users WHERE email = $1
     `
 
-    err := r.db.QueryRow(query, email).Scan(
+    err := r.db.QueryRowContext(ctx, query, email).Scan(
         &user.ID, &user.Email, &user.Password, &user.FirstName,
         &user.LastName, &user.Role, &user.TokenInvalidatedAt, &user.CreatedAt, &user.UpdatedAt,
     )
@@ -50,7 +51,7 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
     return user, err
 }
 
-func (r *UserRepository) FindByID(id string) (*models.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
     user := &models.User{}
 
     query := `
@@ -58,7 +59,7 @@ func (r *UserRepository) FindByID(id string) (*models.User, error) {
         FROM users WHERE id = $1
     `
 
-    err := r.db.QueryRow(query, id).Scan(
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
         &user.ID, &user.Email, &user.Password, &user.FirstName,
         &user.LastName, &user.Role, &user.TokenInvalidatedAt, &user.CreatedAt, &user.UpdatedAt,
     )
@@ -70,8 +71,8 @@ func (r *UserRepository) FindByID(id string) (*models.User, error) {
     return user, err
 }
 
-func (r *UserRepository) InvalidateTokens(userID string) error {
+func (r *userRepository) InvalidateTokens(ctx context.Context, userID string) error {
     query := `UPDATE users SET token_invalidated_at = $1 WHERE id = $2`
-    _, err := r.db.Exec(query, time.Now(), userID)
+    _, err := r.db.ExecContext(ctx, query, time.Now(), userID)
     return err
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+    "context"
     "library-project/config"
     "library-project/internal/dto"
     "library-project/internal/models"
@@ -10,12 +11,12 @@ import (
 )
 
 type AuthService struct {
-    userRepo         *repository.UserRepository
-    refreshTokenRepo *repository.RefreshTokenRepository
+    userRepo         repository.UserRepository
+    refreshTokenRepo repository.RefreshTokenRepository
     cfg              *config.Config
 }
 
-func NewAuthService(userRepo *repository.UserRepository, refreshTokenRepo *repository.RefreshTokenRepository, cfg *config.Config) *AuthService {
+func NewAuthService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, cfg *config.Config) *AuthService {
     return &AuthService{
         userRepo:         userRepo,
         refreshTokenRepo: refreshTokenRepo,
@@ -23,7 +24,7 @@ func NewAuthService(userRepo *repository.UserRepository, refreshTokenRepo *repos
     }
 }
 
-func (s *AuthService) generateTokenPair(user *models.User) (*dto.AuthResponse, error) {
+func (s *AuthService) generateTokenPair(ctx context.Context, user *models.User) (*dto.AuthResponse, error) {
     accessToken, err := utils.GenerateToken(
         user.ID,
         user.Email,
@@ -46,7 +47,7 @@ func (s *AuthService) generateTokenPair(user *models.User) (*dto.AuthResponse, e
         ExpiresAt: time.Now().AddDate(0, 0, s.cfg.JWT.RefreshExpirationDays),
     }
 
-    if err := s.refreshTokenRepo.Create(refreshToken); err != nil {
+    if err := s.refreshTokenRepo.Create(ctx, refreshToken); err != nil {
         return nil, utils.NewInternalServerError("failed to save refresh token", err)
     }
 
@@ -58,7 +59,7 @@ func (s *AuthService) generateTokenPair(user *models.User) (*dto.AuthResponse, e
     }, nil
 }
 
-func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.AuthResponse, error) {
     if err := utils.ValidateEmail(req.Email); err != nil {
         return nil, utils.NewValidationError(err.Error())
     }
@@ -67,7 +68,7 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
         return nil, utils.NewValidationError(err.Error())
     }
 
-    existing, err := s.userRepo.FindByEmail(req.Email)
+    existing, err := s.userRepo.FindByEmail(ctx, req.Email)
     if err != nil {
         return nil, utils.NewInternalServerError("failed to check existing user", err)
     }
@@ -88,15 +89,15 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
         Role:      models.RoleMember,
     }
 
-    if err := s.userRepo.Create(user); err != nil {
+    if err := s.userRepo.Create(ctx, user); err != nil {
         return nil, err
     }
 
-    return s.generateTokenPair(user)
+    return s.generateTokenPair(ctx, user)
 }
 
-func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
-    user, err := s.userRepo.FindByEmail(req.Email)
+func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error) {
+    user, err := s.userRepo.FindByEmail(ctx, req.Email)
     if err != nil {
         return nil, utils.NewInternalServerError("failed to find user", err)
     }
@@ -108,11 +109,11 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
         return nil, utils.NewUnauthorizedError("invalid email or password")
     }
 
-    return s.generateTokenPair(user)
+    return s.generateTokenPair(ctx, user)
 }
 
-func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
-    rt, err := s.refreshTokenRepo.FindByToken(req.RefreshToken)
+func (s *AuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+    rt, err := s.refreshTokenRepo.FindByToken(ctx, req.RefreshToken)
     if err != nil {
         return nil, utils.NewInternalServerError("failed to find refresh token", err)
     }
@@ -121,19 +122,19 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*dto.AuthRespo
     }
 
     if time.Now().After(rt.ExpiresAt) {
-        s.refreshTokenRepo.DeleteByToken(req.RefreshToken)
+        s.refreshTokenRepo.DeleteByToken(ctx, req.RefreshToken)
         return nil, utils.NewUnauthorizedError("refresh token expired")
     }
 
     // Delete old refresh token
-    s.refreshTokenRepo.DeleteByToken(req.RefreshToken)
+    s.refreshTokenRepo.DeleteByToken(ctx, req.RefreshToken)
 
     // Invalidate all old access tokens
-    if err := s.userRepo.InvalidateTokens(rt.UserID); err != nil {
+    if err := s.userRepo.InvalidateTokens(ctx, rt.UserID); err != nil {
         return nil, utils.NewInternalServerError("failed to invalidate tokens", err)
     }
 
-    user, err := s.userRepo.FindByID(rt.UserID)
+    user, err := s.userRepo.FindByID(ctx, rt.UserID)
     if err != nil {
         return nil, utils.NewInternalServerError("failed to find user", err)
     }
@@ -141,14 +142,17 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*dto.AuthRespo
         return nil, utils.NewUnauthorizedError("user not found")
     }
 
-    return s.generateTokenPair(user)
+    return s.generateTokenPair(ctx, user)
 }
 
-func (s *AuthService) Logout(userID string) error {
+func (s *AuthService) Logout(ctx context.Context, userID string) error {
     // Invalidate all access tokens
-    if err := s.userRepo.InvalidateTokens(userID); err != nil {
-        return err
+    if err := s.userRepo.InvalidateTokens(ctx, userID); err != nil {
+        return utils.NewInternalServerError("failed to logout", err)
     }
     // Delete all refresh tokens
-    return s.refreshTokenRepo.DeleteByUserID(userID)
+    if err := s.refreshTokenRepo.DeleteByUserID(ctx, userID); err != nil {
+        return utils.NewInternalServerError("failed to logout", err)
+    }
+    return nil
 }
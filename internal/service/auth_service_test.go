@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"library-project/config"
+	"library-project/internal/dto"
+	"library-project/internal/models"
+	"library-project/internal/repository/memory"
+	"library-project/internal/utils"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testPassword = "Str0ng!Passw0rd"
+
+func testConfig() *config.Config {
+	return &config.Config{
+		JWT: config.JWTConfig{
+			Secret:                "test-secret",
+			ExpirationHours:       1,
+			RefreshExpirationDays: 7,
+			DownloadLinkExpiry:    5 * time.Minute,
+		},
+		Comment: config.CommentConfig{
+			MaxDepth:   2,
+			EditWindow: 15 * time.Minute,
+		},
+	}
+}
+
+func newTestAuthService(store *memory.Store) *AuthService {
+	return NewAuthService(memory.NewUserRepository(store), memory.NewRefreshTokenRepository(store), testConfig())
+}
+
+// assertStatus fails unless err is an AppError with the given HTTP status
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("expected AppError with status %d, got %v", status, err)
+	}
+	if appErr.StatusCode != status {
+		t.Fatalf("expected status %d, got %d (%v)", status, appErr.StatusCode, err)
+	}
+}
+
+func register(t *testing.T, auth *AuthService, email string) *dto.AuthResponse {
+	t.Helper()
+
+	resp, err := auth.Register(t.Context(), &dto.RegisterRequest{
+		Email:     email,
+		Password:  testPassword,
+		FirstName: "Test",
+		LastName:  "User",
+	})
+	if err != nil {
+		t.Fatalf("register %s: %v", email, err)
+	}
+	return resp
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	auth := newTestAuthService(memory.NewStore())
+
+	registered := register(t, auth, "reader@example.com")
+	if registered.AccessToken == "" || registered.RefreshToken == "" {
+		t.Fatal("expected access and refresh tokens")
+	}
+	if registered.User.Role != models.RoleMember {
+		t.Errorf("expected new users to be members, got %q", registered.User.Role)
+	}
+
+	_, err := auth.Register(t.Context(), &dto.RegisterRequest{
+		Email: "reader@example.com", Password: testPassword, FirstName: "Again", LastName: "User",
+	})
+	assertStatus(t, err, http.StatusConflict)
+
+	loggedIn, err := auth.Login(t.Context(), &dto.LoginRequest{Email: "reader@example.com", Password: testPassword})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if loggedIn.User.ID != registered.User.ID {
+		t.Errorf("login returned user %s, want %s", loggedIn.User.ID, registered.User.ID)
+	}
+
+	_, err = auth.Login(t.Context(), &dto.LoginRequest{Email: "reader@example.com", Password: "wrong-password"})
+	assertStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestRegisterRejectsWeakPassword(t *testing.T) {
+	auth := newTestAuthService(memory.NewStore())
+
+	_, err := auth.Register(t.Context(), &dto.RegisterRequest{
+		Email: "weak@example.com", Password: "short", FirstName: "Weak", LastName: "User",
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	store := memory.NewStore()
+	auth := newTestAuthService(store)
+	users := memory.NewUserRepository(store)
+
+	registered := register(t, auth, "rotate@example.com")
+
+	refreshed, err := auth.RefreshToken(t.Context(), &dto.RefreshTokenRequest{RefreshToken: registered.RefreshToken})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if refreshed.RefreshToken == registered.RefreshToken {
+		t.Error("expected a new refresh token")
+	}
+
+	user, err := users.FindByID(t.Context(), registered.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.TokenInvalidatedAt == nil {
+		t.Error("expected earlier access tokens to be invalidated")
+	}
+
+	// The old refresh token is single use
+	_, err = auth.RefreshToken(t.Context(), &dto.RefreshTokenRequest{RefreshToken: registered.RefreshToken})
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	if _, err := auth.RefreshToken(t.Context(), &dto.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}); err != nil {
+		t.Fatalf("refresh with rotated token: %v", err)
+	}
+}
+
+func TestRefreshTokenExpired(t *testing.T) {
+	store := memory.NewStore()
+	auth := newTestAuthService(store)
+	tokens := memory.NewRefreshTokenRepository(store)
+
+	registered := register(t, auth, "expired@example.com")
+
+	expired := &models.RefreshToken{
+		UserID:    registered.User.ID,
+		Token:     "expired-token",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	if err := tokens.Create(t.Context(), expired); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := auth.RefreshToken(t.Context(), &dto.RefreshTokenRequest{RefreshToken: "expired-token"})
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	found, err := tokens.FindByToken(t.Context(), "expired-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != nil {
+		t.Error("expected the expired refresh token to be deleted")
+	}
+}
+
+func TestLogoutRevokesRefreshTokens(t *testing.T) {
+	auth := newTestAuthService(memory.NewStore())
+
+	registered := register(t, auth, "logout@example.com")
+	if err := auth.Logout(t.Context(), registered.User.ID); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+
+	_, err := auth.RefreshToken(t.Context(), &dto.RefreshTokenRequest{RefreshToken: registered.RefreshToken})
+	assertStatus(t, err, http.StatusUnauthorized)
+}
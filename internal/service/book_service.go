@@ -1,79 +1,320 @@
 package service
 
 import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "database/sql"
     "errors"
+    "fmt"
+    "io"
+    "library-project/config"
+    "library-project/internal/cover"
     "library-project/internal/models"
     "library-project/internal/dto"
+    "library-project/internal/pdfmeta"
     "library-project/internal/repository"
+    "library-project/internal/storage"
+    "library-project/internal/utils"
+    "strings"
+    "time"
 )
 
 type BookService struct {
-    bookRepo     *repository.BookRepository
-    categoryRepo *repository.CategoryRepository
-    likeRepo     *repository.LikeRepository
-    savedRepo    *repository.SavedBookRepository
-    commentRepo  *repository.CommentRepository
+    bookRepo        repository.BookRepository
+    categoryRepo    repository.CategoryRepository
+    authorRepo      repository.AuthorRepository
+    tagRepo         repository.TagRepository
+    likeRepo        repository.LikeRepository
+    savedRepo       repository.SavedBookRepository
+    commentRepo     repository.CommentRepository
+    downloadRepo    repository.DownloadRepository
+    commentLikeRepo repository.CommentLikeRepository
+    readingRepo     repository.ReadingRepository
+    bookFileRepo    repository.BookFileRepository
+    userRepo        repository.UserRepository
+    uow             repository.UnitOfWork
+    files           storage.Storage
+    cfg             *config.Config
 }
 
 func NewBookService(
-    bookRepo *repository.BookRepository,
-    categoryRepo *repository.CategoryRepository,
-    likeRepo *repository.LikeRepository,
-    savedRepo *repository.SavedBookRepository,
-    commentRepo *repository.CommentRepository,
+    bookRepo repository.BookRepository,
+    categoryRepo repository.CategoryRepository,
+    authorRepo repository.AuthorRepository,
+    tagRepo repository.TagRepository,
+    likeRepo repository.LikeRepository,
+    savedRepo repository.SavedBookRepository,
+    commentRepo repository.CommentRepository,
+    downloadRepo repository.DownloadRepository,
+    commentLikeRepo repository.CommentLikeRepository,
+    readingRepo repository.ReadingRepository,
+    bookFileRepo repository.BookFileRepository,
+    userRepo repository.UserRepository,
+    uow repository.UnitOfWork,
+    files storage.Storage,
+    cfg *config.Config,
 ) *BookService {
     return &BookService{
-        bookRepo:     bookRepo,
-        categoryRepo: categoryRepo,
-        likeRepo:     likeRepo,
-        savedRepo:    savedRepo,
-        commentRepo:  commentRepo,
+        bookRepo:        bookRepo,
+        categoryRepo:    categoryRepo,
+        authorRepo:      authorRepo,
+        tagRepo:         tagRepo,
+        likeRepo:        likeRepo,
+        savedRepo:       savedRepo,
+        commentRepo:     commentRepo,
+        downloadRepo:    downloadRepo,
+        commentLikeRepo: commentLikeRepo,
+        readingRepo:     readingRepo,
+        bookFileRepo:    bookFileRepo,
+        userRepo:        userRepo,
+        uow:             uow,
+        files:           files,
+        cfg:             cfg,
     }
 }
 
-func (s *BookService) CreateBook(req *dto.CreateBookRequest, pdfFile, ownerID string) (*models.Book, error) {
-    category, err := s.categoryRepo.FindByID(req.CategoryID)
+// CreateBook adds a book for the already stored file, which becomes its first
+// version. meta is the metadata read from PDF files, nil for other formats;
+// it fills in the form fields the owner left empty. coverImage is the key
+// prefix of the already stored cover thumbnails, empty without a cover.
+func (s *BookService) CreateBook(ctx context.Context, req *dto.CreateBookRequest, file *models.BookFile, meta *pdfmeta.Metadata, coverImage, ownerID string) (*models.Book, error) {
+    category, err := s.categoryRepo.FindByID(ctx, req.CategoryID)
     if err != nil {
-        return nil, err
+        return nil, utils.NewInternalServerError("failed to find category", err)
     }
     if category == nil {
-        return nil, errors.New("category not found")
+        return nil, utils.NewValidationError("category not found")
     }
 
     book := &models.Book{
-        Title:       req.Title,
-        Description: req.Description,
-        PDFFile:     pdfFile,
+        Title:       strings.TrimSpace(req.Title),
+        Description: strings.TrimSpace(req.Description),
+        Author:      strings.TrimSpace(req.Author),
+        Keywords:    strings.TrimSpace(req.Keywords),
+        PDFFile:     file.PDFFile,
+        FileHash:    file.FileHash,
+        Format:      file.Format,
+        CoverImage:  coverImage,
+        PageCount:   file.PageCount,
         CategoryID:  req.CategoryID,
         OwnerID:     ownerID,
     }
+    if meta != nil {
+        fillFromMetadata(book, meta)
+    }
+    if book.Title == "" {
+        return nil, utils.NewBadRequestError("title is required, the file does not name one")
+    }
+
+    authorIDs, err := s.validateAuthors(ctx, req.AuthorIDs)
+    if err != nil {
+        return nil, err
+    }
+    tags, err := normalizeTags(req.Tags)
+    if err != nil {
+        return nil, err
+    }
 
-    if err := s.bookRepo.Create(book); err != nil {
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Create(ctx, book); err != nil {
+            return err
+        }
+        if len(authorIDs) == 0 && book.Author != "" {
+            // Without chosen authors, the author named by the form or the
+            // file becomes the author of the book
+            author, err := repos.Authors.FindOrCreateByName(ctx, book.Author)
+            if err != nil {
+                return err
+            }
+            authorIDs = []string{author.ID}
+        }
+        if err := repos.Authors.SetBookAuthors(ctx, book.ID, authorIDs); err != nil {
+            return err
+        }
+        if err := repos.Tags.SetBookTags(ctx, book.ID, tags); err != nil {
+            return err
+        }
+        file.BookID = book.ID
+        file.UploadedBy = ownerID
+        return repos.BookFiles.Create(ctx, file)
+    })
+    if err != nil {
         return nil, err
     }
 
     return book, nil
 }
 
-func (s *BookService) UpdateBook(id string, req *dto.UpdateBookRequest, ownerID string) error {
-    book, err := s.bookRepo.FindByID(id)
+// fillFromMetadata sets the book's empty fields from the metadata of its
+// file. The subject doubles as the description.
+func fillFromMetadata(book *models.Book, meta *pdfmeta.Metadata) {
+    if book.Title == "" {
+        book.Title = truncate(meta.Title, maxTitleLength)
+    }
+    if book.Description == "" {
+        book.Description = meta.Subject
+    }
+    if book.Author == "" {
+        book.Author = truncate(meta.Author, maxAuthorLength)
+    }
+    if book.Keywords == "" {
+        book.Keywords = meta.Keywords
+    }
+    book.Subject = meta.Subject
+    book.DocumentCreatedAt = meta.CreatedAt
+}
+
+// Column sizes of the book fields metadata may fill in
+const (
+    maxTitleLength  = 255
+    maxAuthorLength = 255
+)
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+    if runes := []rune(s); len(runes) > n {
+        return strings.TrimSpace(string(runes[:n]))
+    }
+    return s
+}
+
+// ReplaceBookFile makes the already stored file the new version of the
+// book's file. Earlier versions are kept for GetBookFiles and RollbackBookFile.
+func (s *BookService) ReplaceBookFile(ctx context.Context, bookID, ownerID string, file *models.BookFile) (*models.BookFile, error) {
+    if _, err := s.findOwnedBook(ctx, bookID, ownerID, "replace its file"); err != nil {
+        return nil, err
+    }
+
+    file.BookID = bookID
+    file.UploadedBy = ownerID
+    if err := s.addBookFile(ctx, file); err != nil {
+        return nil, err
+    }
+
+    return file, nil
+}
+
+// GetBookFiles lists the versions of the book's file, newest first
+func (s *BookService) GetBookFiles(ctx context.Context, bookID, ownerID string) ([]*models.BookFile, error) {
+    if _, err := s.findOwnedBook(ctx, bookID, ownerID, "see its file versions"); err != nil {
+        return nil, err
+    }
+
+    files, err := s.bookFileRepo.FindByBookID(ctx, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book files", err)
+    }
+    return files, nil
+}
+
+// GetBookFile returns the book together with one version of its file
+func (s *BookService) GetBookFile(ctx context.Context, bookID string, version int, ownerID string) (*models.BookWithCategory, *models.BookFile, error) {
+    book, err := s.findOwnedBook(ctx, bookID, ownerID, "download its file versions")
     if err != nil {
-        return err
+        return nil, nil, err
+    }
+
+    file, err := s.bookFileRepo.FindByVersion(ctx, bookID, version)
+    if err != nil {
+        return nil, nil, utils.NewInternalServerError("failed to find book file", err)
+    }
+    if file == nil {
+        return nil, nil, utils.NewNotFoundError("file version")
+    }
+    return book, file, nil
+}
+
+// RollbackBookFile makes the file of an earlier version current again. The
+// history is not rewritten: the file is added as a new version that records
+// which version it was restored from.
+func (s *BookService) RollbackBookFile(ctx context.Context, bookID string, version int, ownerID string) (*models.BookFile, error) {
+    book, previous, err := s.GetBookFile(ctx, bookID, version, ownerID)
+    if err != nil {
+        return nil, err
+    }
+    if previous.PDFFile == book.PDFFile {
+        return nil, utils.NewBadRequestError(fmt.Sprintf("version %d is the current file", version))
+    }
+
+    file := &models.BookFile{
+        BookID:       bookID,
+        PDFFile:      previous.PDFFile,
+        FileHash:     previous.FileHash,
+        Format:       previous.Format,
+        PageCount:    previous.PageCount,
+        UploadedBy:   ownerID,
+        RestoredFrom: &previous.Version,
+    }
+    if err := s.addBookFile(ctx, file); err != nil {
+        return nil, err
+    }
+
+    return file, nil
+}
+
+// addBookFile stores file as the next version and makes it the book's current file
+func (s *BookService) addBookFile(ctx context.Context, file *models.BookFile) error {
+    return s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Lock(ctx, file.BookID); err != nil {
+            return utils.NewInternalServerError("failed to lock book", err)
+        }
+        if err := repos.BookFiles.Create(ctx, file); err != nil {
+            return utils.NewInternalServerError("failed to add file version", err)
+        }
+        if err := repos.Books.UpdateFile(ctx, file); err != nil {
+            return utils.NewInternalServerError("failed to update book file", err)
+        }
+        return nil
+    })
+}
+
+// findOwnedBook returns the book if ownerID owns it; action completes the
+// message of the error returned otherwise
+func (s *BookService) findOwnedBook(ctx context.Context, bookID, ownerID, action string) (*models.BookWithCategory, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book", err)
     }
     if book == nil {
-        return errors.New("book not found")
+        return nil, utils.NewNotFoundError("book")
     }
     if book.OwnerID != ownerID {
-        return errors.New("unauthorized")
+        return nil, utils.NewForbiddenError("only the owner of a book can " + action)
+    }
+    return book, nil
+}
+
+// UpdateBook changes the book's details, and its authors and tags when the
+// request lists them. A non-empty coverImage, the key
+// prefix of already stored cover thumbnails, replaces the book's cover; the
+// thumbnails of the previous cover are removed.
+func (s *BookService) UpdateBook(ctx context.Context, id string, req *dto.UpdateBookRequest, coverImage, ownerID string) error {
+    book, err := s.findOwnedBook(ctx, id, ownerID, "update it")
+    if err != nil {
+        return err
     }
 
     if req.CategoryID != "" {
-        category, err := s.categoryRepo.FindByID(req.CategoryID)
+        category, err := s.categoryRepo.FindByID(ctx, req.CategoryID)
         if err != nil {
-            return err
+            return utils.NewInternalServerError("failed to find category", err)
         }
         if category == nil {
-            return errors.New("category not found")
+            return utils.NewValidationError("category not found")
+        }
+    }
+
+    var authorIDs []string
+    if req.AuthorIDs != nil {
+        if authorIDs, err = s.validateAuthors(ctx, *req.AuthorIDs); err != nil {
+            return err
+        }
+    }
+    var tags []string
+    if req.Tags != nil {
+        if tags, err = normalizeTags(*req.Tags); err != nil {
+            return err
         }
     }
 
@@ -84,33 +325,209 @@ func (s *BookService) UpdateBook(id string, req *dto.UpdateBookRequest, ownerID
         CategoryID:  req.CategoryID,
     }
 
-    return s.bookRepo.Update(updatedBook)
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Update(ctx, updatedBook); err != nil {
+            return err
+        }
+        if req.AuthorIDs != nil {
+            if err := repos.Authors.SetBookAuthors(ctx, id, authorIDs); err != nil {
+                return err
+            }
+        }
+        if req.Tags != nil {
+            if err := repos.Tags.SetBookTags(ctx, id, tags); err != nil {
+                return err
+            }
+        }
+        if coverImage == "" {
+            return nil
+        }
+        return repos.Books.UpdateCover(ctx, id, coverImage)
+    })
+    if err != nil {
+        return err
+    }
+
+    if coverImage != "" && book.CoverImage != "" {
+        // The book no longer refers to the old thumbnails. Ones that cannot
+        // be removed are found again by FindOrphanedFiles.
+        if err := s.deleteCover(context.WithoutCancel(ctx), book.CoverImage); err != nil {
+            utils.LogError(err, "Failed to remove replaced cover", map[string]interface{}{
+                "book_id": id,
+                "cover":   book.CoverImage,
+            })
+        }
+    }
+    return nil
+}
+
+// deleteCover removes every thumbnail of the cover stored under coverImage
+func (s *BookService) deleteCover(ctx context.Context, coverImage string) error {
+    var errs []error
+    for _, key := range cover.Keys(coverImage) {
+        if err := s.files.Delete(ctx, key); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errors.Join(errs...)
 }
 
-func (s *BookService) DeleteBook(id, ownerID string) error {
-    book, err := s.bookRepo.FindByID(id)
-    if err != nil {
+func (s *BookService) DeleteBook(ctx context.Context, id, ownerID string) error {
+    if _, err := s.findOwnedBook(ctx, id, ownerID, "delete it"); err != nil {
         return err
     }
+
+    if err := s.bookRepo.Delete(ctx, id); err != nil {
+        return utils.NewInternalServerError("failed to delete book", err)
+    }
+    return nil
+}
+
+// RestoreBook brings back a deleted book that has not been purged yet
+func (s *BookService) RestoreBook(ctx context.Context, id, ownerID string) (*models.BookWithCategory, error) {
+    book, err := s.bookRepo.FindDeletedByID(ctx, id)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book", err)
+    }
     if book == nil {
-        return errors.New("book not found")
+        return nil, utils.NewNotFoundError("deleted book")
     }
     if book.OwnerID != ownerID {
-        return errors.New("unauthorized")
+        return nil, utils.NewForbiddenError("only the owner can restore a book")
+    }
+
+    if err := s.bookRepo.Restore(ctx, id); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return nil, utils.NewNotFoundError("deleted book")
+        }
+        return nil, utils.NewInternalServerError("failed to restore book", err)
+    }
+
+    return s.bookRepo.FindByID(ctx, id)
+}
+
+// PurgeDeletedBooks permanently removes the books deleted longer than retention
+// ago together with the files of all their versions and their cover thumbnails
+// and returns how many were purged. A file that
+// cannot be removed does not stop the purge; it is reported in the returned
+// error and found again by FindOrphanedFiles.
+func (s *BookService) PurgeDeletedBooks(ctx context.Context, retention time.Duration) (int, error) {
+    books, err := s.bookRepo.FindDeletedOlderThan(ctx, retention)
+    if err != nil {
+        return 0, err
+    }
+
+    purged := 0
+    var fileErrs []error
+    for _, book := range books {
+        versions, err := s.bookFileRepo.FindByBookID(ctx, book.ID)
+        if err != nil {
+            return purged, errors.Join(append(fileErrs, err)...)
+        }
+
+        if err := s.bookRepo.Purge(ctx, book.ID); err != nil {
+            return purged, errors.Join(append(fileErrs, err)...)
+        }
+        purged++
+
+        // The row goes first so a book never points at a missing file.
+        // Rollbacks share files between versions, delete each one once.
+        files := map[string]bool{book.PDFFile: true}
+        for _, version := range versions {
+            files[version.PDFFile] = true
+        }
+        if book.CoverImage != "" {
+            for _, key := range cover.Keys(book.CoverImage) {
+                files[key] = true
+            }
+        }
+        for file := range files {
+            if err := s.files.Delete(ctx, file); err != nil {
+                fileErrs = append(fileErrs, err)
+            }
+        }
+    }
+
+    return purged, errors.Join(fileErrs...)
+}
+
+// orphanGracePeriod keeps files that were uploaded moments ago, and whose book
+// may not have been inserted yet, from being reported as orphans
+const orphanGracePeriod = time.Hour
+
+// FindOrphanedFiles lists the stored files, book files and cover thumbnails,
+// that no book, deleted or not, refers to
+func (s *BookService) FindOrphanedFiles(ctx context.Context) ([]string, error) {
+    files, err := s.bookRepo.FindAllFiles(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    referenced := make(map[string]bool, len(files))
+    for _, file := range files {
+        referenced[file] = true
+    }
+
+    covers, err := s.bookRepo.FindAllCovers(ctx)
+    if err != nil {
+        return nil, err
+    }
+    for _, coverImage := range covers {
+        for _, key := range cover.Keys(coverImage) {
+            referenced[key] = true
+        }
+    }
+
+    objects, err := s.files.List(ctx)
+    if err != nil {
+        return nil, err
     }
 
-    return s.bookRepo.Delete(id)
+    var orphans []string
+    for _, object := range objects {
+        if referenced[object.Key] || time.Since(object.ModTime) < orphanGracePeriod {
+            continue
+        }
+        orphans = append(orphans, object.Key)
+    }
+
+    return orphans, nil
+}
+
+// FileHash returns the hex encoded SHA-256 of the book's file. Hashes of files
+// uploaded before they were recorded are computed once and stored.
+func (s *BookService) FileHash(ctx context.Context, book *models.BookWithCategory) (string, error) {
+    if book.FileHash != "" {
+        return book.FileHash, nil
+    }
+
+    reader, _, err := s.files.Get(ctx, book.PDFFile)
+    if err != nil {
+        return "", err
+    }
+    defer reader.Close()
+
+    hash := sha256.New()
+    if _, err := io.Copy(hash, reader); err != nil {
+        return "", err
+    }
+    book.FileHash = hex.EncodeToString(hash.Sum(nil))
+
+    if err := s.bookRepo.UpdateFileHash(ctx, book.ID, book.FileHash); err != nil {
+        return "", err
+    }
+    return book.FileHash, nil
 }
 
-func (s *BookService) GetBook(id string) (*models.BookWithCategory, error) {
-    return s.bookRepo.FindByID(id)
+func (s *BookService) GetBook(ctx context.Context, id string) (*models.BookWithCategory, error) {
+    return s.bookRepo.FindByID(ctx, id)
 }
 
-func (s *BookService) GetAllBooks() ([]*models.BookWithCategory, error) {
-    return s.bookRepo.FindAll()
+func (s *BookService) GetAllBooks(ctx context.Context) ([]*models.BookWithCategory, error) {
+    return s.bookRepo.FindAll(ctx)
 }
 
-func (s *BookService) GetAllBooksPaginated(page, pageSize int) ([]*models.BookWithCategory, int, error) {
+func (s *BookService) GetAllBooksPaginated(ctx context.Context, page, pageSize int, sortBy, order string) ([]*models.BookWithCategory, int, error) {
     if page < 1 {
         page = 1
     }
@@ -119,12 +536,12 @@ func (s *BookService) GetAllBooksPaginated(page, pageSize int) ([]*models.BookWi
     }
 
     offset := (page - 1) * pageSize
-    books, err := s.bookRepo.FindAllPaginated(pageSize, offset)
+    books, err := s.bookRepo.FindAllPaginated(ctx, pageSize, offset, repository.BookSort{Field: sortBy, Order: order})
     if err != nil {
         return nil, 0, err
     }
 
-    total, err := s.bookRepo.CountAll()
+    total, err := s.bookRepo.CountAll(ctx)
     if err != nil {
         return nil, 0, err
     }
@@ -132,11 +549,39 @@ func (s *BookService) GetAllBooksPaginated(page, pageSize int) ([]*models.BookWi
     return books, total, nil
 }
 
-func (s *BookService) GetBooksByCategory(categoryID string) ([]*models.BookWithCategory, error) {
-    return s.bookRepo.FindByCategory(categoryID)
+func (s *BookService) SearchBooks(ctx context.Context, filter *dto.BookFilterRequest) ([]*models.BookWithCategory, int, error) {
+    if filter.Page < 1 {
+        filter.Page = 1
+    }
+    if filter.PageSize < 1 || filter.PageSize > 100 {
+        filter.PageSize = 20
+    }
+
+    tags, err := normalizeTags([]string{filter.Tags})
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return s.bookRepo.Search(ctx, repository.BookSearchFilter{
+        Search:       filter.Search,
+        CategoryID:   filter.CategoryID,
+        MinPages:     filter.MinPages,
+        MaxPages:     filter.MaxPages,
+        Tags:         tags,
+        MatchAllTags: filter.Match != "any",
+        Sort:         repository.BookSort{Field: filter.SortBy, Order: filter.Order},
+        Limit:        filter.PageSize,
+        Offset:       (filter.Page - 1) * filter.PageSize,
+    })
+}
+
+func (s *BookService) GetBooksByCategory(ctx context.Context, categoryID string) ([]*models.BookWithCategory, error) {
+    return s.bookRepo.FindByCategory(ctx, categoryID)
 }
 
-func (s *BookService) GetBooksByCategoryPaginated(categoryID string, page, pageSize int) ([]*models.BookWithCategory, int, error) {
+// GetBooksByCategoryPaginated lists the books of a category. With includeDescendants
+// the books of all nested subcategories are included as well.
+func (s *BookService) GetBooksByCategoryPaginated(ctx context.Context, categoryID string, page, pageSize int, sortBy, order string, includeDescendants bool) ([]*models.BookWithCategory, int, error) {
     if page < 1 {
         page = 1
     }
@@ -145,12 +590,26 @@ func (s *BookService) GetBooksByCategoryPaginated(categoryID string, page, pageS
     }
 
     offset := (page - 1) * pageSize
-    books, err := s.bookRepo.FindByCategoryPaginated(categoryID, pageSize, offset)
+    sort := repository.BookSort{Field: sortBy, Order: order}
+
+    var books []*models.BookWithCategory
+    var total int
+    var err error
+
+    if includeDescendants {
+        books, err = s.bookRepo.FindByCategoryTreePaginated(ctx, categoryID, pageSize, offset, sort)
+    } else {
+        books, err = s.bookRepo.FindByCategoryPaginated(ctx, categoryID, pageSize, offset, sort)
+    }
     if err != nil {
         return nil, 0, err
     }
 
-    total, err := s.bookRepo.CountByCategory(categoryID)
+    if includeDescendants {
+        total, err = s.bookRepo.CountByCategoryTree(ctx, categoryID)
+    } else {
+        total, err = s.bookRepo.CountByCategory(ctx, categoryID)
+    }
     if err != nil {
         return nil, 0, err
     }
@@ -158,13 +617,13 @@ func (s *BookService) GetBooksByCategoryPaginated(categoryID string, page, pageS
     return books, total, nil
 }
 
-func (s *BookService) SaveBook(userID, bookID string) error {
-    book, err := s.bookRepo.FindByID(bookID)
+func (s *BookService) SaveBook(ctx context.Context, userID, bookID string) error {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
     if err != nil {
-        return err
+        return utils.NewInternalServerError("failed to find book", err)
     }
     if book == nil {
-        return errors.New("book not found")
+        return utils.NewNotFoundError("book")
     }
 
     saved := &models.SavedBook{
@@ -172,32 +631,45 @@ func (s *BookService) SaveBook(userID, bookID string) error {
         BookID: bookID,
     }
 
-    if err := s.savedRepo.Create(saved); err != nil {
-        return err
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Lock(ctx, bookID); err != nil {
+            return err
+        }
+        if err := repos.SavedBooks.Create(ctx, saved); err != nil {
+            return err
+        }
+        return repos.Books.UpdateSaveCount(ctx, bookID)
+    })
+    // Saving a book twice inserts nothing
+    if errors.Is(err, sql.ErrNoRows) {
+        return utils.NewConflictError("book is already saved")
     }
-
-    return s.bookRepo.UpdateSaveCount(bookID)
+    return err
 }
 
-func (s *BookService) UnsaveBook(userID, bookID string) error {
-    if err := s.savedRepo.Delete(userID, bookID); err != nil {
-        return err
-    }
-
-    return s.bookRepo.UpdateSaveCount(bookID)
+func (s *BookService) UnsaveBook(ctx context.Context, userID, bookID string) error {
+    return s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Lock(ctx, bookID); err != nil {
+            return err
+        }
+        if err := repos.SavedBooks.Delete(ctx, userID, bookID); err != nil {
+            return err
+        }
+        return repos.Books.UpdateSaveCount(ctx, bookID)
+    })
 }
 
-func (s *BookService) GetSavedBooks(userID string) ([]*models.BookWithCategory, error) {
-    return s.savedRepo.FindByUserID(userID)
+func (s *BookService) GetSavedBooks(ctx context.Context, userID, sortBy, order string) ([]*models.BookWithCategory, error) {
+    return s.savedRepo.FindByUserID(ctx, userID, repository.BookSort{Field: sortBy, Order: order})
 }
 
-func (s *BookService) LikeBook(userID, bookID string, isLike bool) error {
-    book, err := s.bookRepo.FindByID(bookID)
+func (s *BookService) LikeBook(ctx context.Context, userID, bookID string, isLike bool) (*dto.LikeResponse, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
     if err != nil {
-        return err
+        return nil, utils.NewInternalServerError("failed to find book", err)
     }
     if book == nil {
-        return errors.New("book not found")
+        return nil, utils.NewNotFoundError("book")
     }
 
     like := &models.Like{
@@ -206,81 +678,965 @@ func (s *BookService) LikeBook(userID, bookID string, isLike bool) error {
         IsLike: isLike,
     }
 
-    if err := s.likeRepo.Upsert(like); err != nil {
-        return err
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Lock(ctx, bookID); err != nil {
+            return err
+        }
+        if err := repos.Likes.Upsert(ctx, like); err != nil {
+            return err
+        }
+        return repos.Books.UpdateLikeCount(ctx, bookID)
+    })
+    if err != nil {
+        return nil, err
     }
 
-    return s.bookRepo.UpdateLikeCount(bookID)
+    return s.likeResponse(ctx, bookID, isLike, !isLike)
 }
 
-func (s *BookService) RemoveLike(userID, bookID string) error {
-    if err := s.likeRepo.Delete(userID, bookID); err != nil {
-        return err
+func (s *BookService) RemoveLike(ctx context.Context, userID, bookID string) (*dto.LikeResponse, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book", err)
+    }
+    if book == nil {
+        return nil, utils.NewNotFoundError("book")
+    }
+
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Books.Lock(ctx, bookID); err != nil {
+            return err
+        }
+        if err := repos.Likes.Delete(ctx, userID, bookID); err != nil {
+            return err
+        }
+        return repos.Books.UpdateLikeCount(ctx, bookID)
+    })
+    if err != nil {
+        return nil, err
     }
 
-    return s.bookRepo.UpdateLikeCount(bookID)
+    return s.likeResponse(ctx, bookID, false, false)
 }
 
-func (s *BookService) AddComment(userID, bookID, content string, parentID *string) (*models.Comment, error) {
-    book, err := s.bookRepo.FindByID(bookID)
+// likeResponse reports the book's like counters together with the user's vote
+func (s *BookService) likeResponse(ctx context.Context, bookID string, liked, disliked bool) (*dto.LikeResponse, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
     if err != nil {
-        return nil, err
+        return nil, utils.NewInternalServerError("failed to find book", err)
     }
     if book == nil {
-        return nil, errors.New("book not found")
+        return nil, utils.NewNotFoundError("book")
     }
 
-    comment := &models.Comment{
-        BookID:   bookID,
-        UserID:   userID,
-        Content:  content,
-        ParentID: parentID,
+    return &dto.LikeResponse{
+        BookID:       bookID,
+        UserLiked:    liked,
+        UserDisliked: disliked,
+        LikeCount:    book.LikeCount,
+        DislikeCount: book.DislikeCount,
+    }, nil
+}
+
+// MapBooksForUser converts books to responses including the user's own like and
+// save state. The state of all books is loaded with one query each for likes
+// and saves regardless of the number of books.
+func (s *BookService) MapBooksForUser(ctx context.Context, userID string, books []*models.BookWithCategory) ([]dto.BookResponse, error) {
+    responses := utils.MapBooksToResponse(books)
+    if len(books) == 0 {
+        return responses, nil
     }
 
-    if err := s.commentRepo.Create(comment); err != nil {
+    bookIDs := make([]string, len(books))
+    for i, book := range books {
+        bookIDs[i] = book.ID
+    }
+
+    likes, err := s.likeRepo.FindUserLikes(ctx, userID, bookIDs)
+    if err != nil {
         return nil, err
     }
 
-    return comment, nil
+    saved, err := s.savedRepo.FindSavedBookIDs(ctx, userID, bookIDs)
+    if err != nil {
+        return nil, err
+    }
+
+    for i := range responses {
+        if isLike, ok := likes[responses[i].ID]; ok {
+            responses[i].UserLiked = isLike
+            responses[i].UserDisliked = !isLike
+        }
+        responses[i].UserSaved = saved[responses[i].ID]
+    }
+
+    return responses, nil
+}
+
+func (s *BookService) RecordDownload(ctx context.Context, userID, bookID, ipAddress, userAgent string) error {
+    download := &models.Download{
+        BookID:    bookID,
+        UserID:    userID,
+        IPAddress: ipAddress,
+        UserAgent: userAgent,
+    }
+
+    return s.downloadRepo.Create(ctx, download)
 }
 
-func (s *BookService) GetComments(bookID string) ([]*models.CommentWithUser, error) {
-    return s.commentRepo.FindByBookID(bookID)
+// CreateDownloadLink issues a token that lets the user download the book
+// without an access token until the returned expiry
+func (s *BookService) CreateDownloadLink(ctx context.Context, bookID, userID string) (string, time.Time, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return "", time.Time{}, utils.NewInternalServerError("failed to find book", err)
+    }
+    if book == nil {
+        return "", time.Time{}, utils.NewNotFoundError("book")
+    }
+
+    expiresAt := time.Now().Add(s.cfg.JWT.DownloadLinkExpiry).Truncate(time.Second)
+    token, err := utils.GenerateDownloadToken(userID, bookID, s.cfg.JWT.Secret, expiresAt)
+    if err != nil {
+        return "", time.Time{}, utils.NewInternalServerError("failed to sign download link", err)
+    }
+
+    return token, expiresAt, nil
 }
 
-func (s *BookService) CreateCategory(req *dto.CreateCategoryRequest) (*models.Category, error) {
-    category := &models.Category{
-        Name:        req.Name,
-        Description: req.Description,
+// ResolveDownloadLink checks the signature and expiry of a download token.
+// Like access tokens, links issued before the user logged out or refreshed
+// their tokens are no longer accepted.
+func (s *BookService) ResolveDownloadLink(ctx context.Context, token string) (*utils.DownloadClaims, error) {
+    claims, err := utils.ValidateDownloadToken(token, s.cfg.JWT.Secret)
+    if err != nil {
+        return nil, utils.NewForbiddenError("invalid or expired download link")
     }
 
-    if err := s.categoryRepo.Create(category); err != nil {
-        return nil, err
+    user, err := s.userRepo.FindByID(ctx, claims.UserID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find user", err)
+    }
+    if user == nil {
+        return nil, utils.NewForbiddenError("invalid or expired download link")
+    }
+    if user.TokenInvalidatedAt != nil && claims.IssuedAt != nil && claims.IssuedAt.Time.Before(*user.TokenInvalidatedAt) {
+        return nil, utils.NewForbiddenError("download link has been invalidated")
     }
 
-    return category, nil
+    return claims, nil
 }
 
-func (s *BookService) GetAllCategories() ([]*models.Category, error) {
-    return s.categoryRepo.FindAll()
+// RecordView counts the book as viewed in the web reader
+func (s *BookService) RecordView(ctx context.Context, userID, bookID, ipAddress, userAgent string) error {
+    view := &models.BookView{
+        BookID:    bookID,
+        UserID:    userID,
+        IPAddress: ipAddress,
+        UserAgent: userAgent,
+    }
+
+    return s.readingRepo.CreateView(ctx, view)
 }
 
-func (s *BookService) DeleteCategory(categoryID string) error {
-    category, err := s.categoryRepo.FindByID(categoryID)
+// SaveReadingProgress remembers the last page the user read of the book
+func (s *BookService) SaveReadingProgress(ctx context.Context, userID, bookID string, page int) (*models.ReadingProgress, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
     if err != nil {
-        return err
+        return nil, utils.NewInternalServerError("failed to find book", err)
     }
-    if category == nil {
-        return errors.New("category not found")
+    if book == nil {
+        return nil, utils.NewNotFoundError("book")
     }
 
-    count, err := s.bookRepo.CountByCategory(categoryID)
-    if err != nil {
-        return err
+    progress := &models.ReadingProgress{
+        UserID: userID,
+        BookID: bookID,
+        Page:   page,
     }
-    if count > 0 {
-        return errors.New("cannot delete category with existing books")
+    if err := s.readingRepo.UpsertProgress(ctx, progress); err != nil {
+        return nil, utils.NewInternalServerError("failed to save reading progress", err)
     }
 
-    return s.categoryRepo.Delete(categoryID)
-}
\ No newline at end of file
+    return progress, nil
+}
+
+func (s *BookService) GetReadingProgress(ctx context.Context, userID, bookID string) (*models.ReadingProgress, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book", err)
+    }
+    if book == nil {
+        return nil, utils.NewNotFoundError("book")
+    }
+
+    progress, err := s.readingRepo.FindProgress(ctx, userID, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find reading progress", err)
+    }
+    if progress == nil {
+        return nil, utils.NewNotFoundError("reading progress")
+    }
+
+    return progress, nil
+}
+
+func (s *BookService) GetBookDownloads(ctx context.Context, bookID, ownerID string, page, pageSize int) ([]*models.DownloadWithUser, int, error) {
+    if _, err := s.findOwnedBook(ctx, bookID, ownerID, "see its downloads"); err != nil {
+        return nil, 0, err
+    }
+
+    if page < 1 {
+        page = 1
+    }
+    if pageSize < 1 || pageSize > 100 {
+        pageSize = 20
+    }
+
+    offset := (page - 1) * pageSize
+    downloads, err := s.downloadRepo.FindByBookID(ctx, bookID, pageSize, offset)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    total, err := s.downloadRepo.CountByBookID(ctx, bookID)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return downloads, total, nil
+}
+
+func (s *BookService) AddComment(ctx context.Context, userID, bookID, content string, parentID *string) (*models.Comment, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book", err)
+    }
+    if book == nil {
+        return nil, utils.NewNotFoundError("book")
+    }
+
+    depth := 0
+    if parentID != nil && *parentID == "" {
+        parentID = nil
+    }
+    if parentID != nil {
+        parent, err := s.commentRepo.FindByID(ctx, *parentID)
+        if err != nil {
+            return nil, utils.NewInternalServerError("failed to find parent comment", err)
+        }
+        if parent == nil {
+            return nil, utils.NewNotFoundError("parent comment")
+        }
+        if parent.BookID != bookID {
+            return nil, utils.NewValidationError("parent comment belongs to a different book")
+        }
+        if parent.DeletedAt != nil {
+            return nil, utils.NewValidationError("cannot reply to a deleted comment")
+        }
+
+        depth = parent.Depth + 1
+        if depth > s.cfg.Comment.MaxDepth {
+            return nil, utils.NewValidationError(fmt.Sprintf("replies cannot be nested deeper than %d levels", s.cfg.Comment.MaxDepth))
+        }
+    }
+
+    comment := &models.Comment{
+        BookID:   bookID,
+        UserID:   userID,
+        Content:  content,
+        ParentID: parentID,
+        Depth:    depth,
+    }
+
+    if err := s.commentRepo.Create(ctx, comment); err != nil {
+        return nil, utils.NewInternalServerError("failed to create comment", err)
+    }
+
+    return comment, nil
+}
+
+// findBookComment returns the comment if it exists, belongs to the book and is not deleted
+func (s *BookService) findBookComment(ctx context.Context, bookID, commentID string) (*models.Comment, error) {
+    comment, err := s.commentRepo.FindByID(ctx, commentID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find comment", err)
+    }
+    if comment == nil || comment.BookID != bookID || comment.DeletedAt != nil {
+        return nil, utils.NewNotFoundError("comment")
+    }
+    return comment, nil
+}
+
+// UpdateComment lets the author change a comment within the configured edit window
+func (s *BookService) UpdateComment(ctx context.Context, userID, bookID, commentID, content string) (*models.Comment, error) {
+    comment, err := s.findBookComment(ctx, bookID, commentID)
+    if err != nil {
+        return nil, err
+    }
+    if comment.UserID != userID {
+        return nil, utils.NewForbiddenError("only the author can edit a comment")
+    }
+
+    comment.Content = content
+    if err := s.commentRepo.Update(ctx, comment, s.cfg.Comment.EditWindow); err != nil {
+        if err == sql.ErrNoRows {
+            return nil, utils.NewForbiddenError(fmt.Sprintf(
+                "comments can only be edited within %d minutes of posting", int(s.cfg.Comment.EditWindow.Minutes())))
+        }
+        return nil, utils.NewInternalServerError("failed to update comment", err)
+    }
+
+    return comment, nil
+}
+
+// DeleteComment removes a comment. Authors can delete their own comments and the
+// book's owner can delete any comment on the book. A comment that still has
+// replies becomes a tombstone; tombstones left without replies are removed.
+func (s *BookService) DeleteComment(ctx context.Context, userID, bookID, commentID string) error {
+    comment, err := s.findBookComment(ctx, bookID, commentID)
+    if err != nil {
+        return err
+    }
+
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return utils.NewInternalServerError("failed to find book", err)
+    }
+    if book == nil {
+        return utils.NewNotFoundError("book")
+    }
+    if comment.UserID != userID && book.OwnerID != userID {
+        return utils.NewForbiddenError("only the author or the book owner can delete a comment")
+    }
+
+    return s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Comments.Lock(ctx, comment.ID); err != nil {
+            return utils.NewInternalServerError("failed to lock comment", err)
+        }
+
+        replies, err := repos.Comments.CountReplies(ctx, comment.ID)
+        if err != nil {
+            return utils.NewInternalServerError("failed to count replies", err)
+        }
+        if replies > 0 {
+            if err := repos.Comments.SoftDelete(ctx, comment.ID); err != nil {
+                return utils.NewInternalServerError("failed to delete comment", err)
+            }
+            return nil
+        }
+
+        if err := repos.Comments.Delete(ctx, comment.ID); err != nil {
+            return utils.NewInternalServerError("failed to delete comment", err)
+        }
+
+        return pruneDeletedAncestors(ctx, repos.Comments, comment.ParentID)
+    })
+}
+
+// LikeComment adds or changes the user's like or dislike on a comment
+func (s *BookService) LikeComment(ctx context.Context, userID, commentID string, isLike bool) (*dto.CommentLikeResponse, error) {
+    comment, err := s.commentRepo.FindByID(ctx, commentID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find comment", err)
+    }
+    if comment == nil || comment.DeletedAt != nil {
+        return nil, utils.NewNotFoundError("comment")
+    }
+
+    like := &models.CommentLike{
+        CommentID: commentID,
+        UserID:    userID,
+        IsLike:    isLike,
+    }
+
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Comments.Lock(ctx, commentID); err != nil {
+            return utils.NewInternalServerError("failed to lock comment", err)
+        }
+        if err := repos.CommentLikes.Upsert(ctx, like); err != nil {
+            return utils.NewInternalServerError("failed to save like", err)
+        }
+        if err := repos.Comments.UpdateLikeCount(ctx, commentID); err != nil {
+            return utils.NewInternalServerError("failed to update like count", err)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return s.commentLikeResponse(ctx, commentID, isLike, !isLike)
+}
+
+// RemoveCommentLike clears the user's like or dislike on a comment
+func (s *BookService) RemoveCommentLike(ctx context.Context, userID, commentID string) (*dto.CommentLikeResponse, error) {
+    comment, err := s.commentRepo.FindByID(ctx, commentID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find comment", err)
+    }
+    if comment == nil {
+        return nil, utils.NewNotFoundError("comment")
+    }
+
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        if err := repos.Comments.Lock(ctx, commentID); err != nil {
+            return utils.NewInternalServerError("failed to lock comment", err)
+        }
+        if err := repos.CommentLikes.Delete(ctx, userID, commentID); err != nil {
+            return utils.NewInternalServerError("failed to remove like", err)
+        }
+        if err := repos.Comments.UpdateLikeCount(ctx, commentID); err != nil {
+            return utils.NewInternalServerError("failed to update like count", err)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return s.commentLikeResponse(ctx, commentID, false, false)
+}
+
+// commentLikeResponse reports the denormalized counters of a comment
+func (s *BookService) commentLikeResponse(ctx context.Context, commentID string, liked, disliked bool) (*dto.CommentLikeResponse, error) {
+    comment, err := s.commentRepo.FindByID(ctx, commentID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find comment", err)
+    }
+    if comment == nil {
+        return nil, utils.NewNotFoundError("comment")
+    }
+
+    return &dto.CommentLikeResponse{
+        CommentID:    commentID,
+        UserLiked:    liked,
+        UserDisliked: disliked,
+        LikeCount:    comment.LikeCount,
+        DislikeCount: comment.DislikeCount,
+    }, nil
+}
+
+// pruneDeletedAncestors walks up from parentID removing tombstones that no longer have replies
+func pruneDeletedAncestors(ctx context.Context, comments repository.CommentRepository, parentID *string) error {
+    for parentID != nil {
+        parent, err := comments.FindByID(ctx, *parentID)
+        if err != nil {
+            return utils.NewInternalServerError("failed to find parent comment", err)
+        }
+        if parent == nil || parent.DeletedAt == nil {
+            return nil
+        }
+
+        replies, err := comments.CountReplies(ctx, parent.ID)
+        if err != nil {
+            return utils.NewInternalServerError("failed to count replies", err)
+        }
+        if replies > 0 {
+            return nil
+        }
+
+        if err := comments.Delete(ctx, parent.ID); err != nil {
+            return utils.NewInternalServerError("failed to delete comment", err)
+        }
+        parentID = parent.ParentID
+    }
+
+    return nil
+}
+
+// GetComments returns a page of top-level comment threads of a book together
+// with all of their replies. With flat the threads are returned depth-first
+// as a single list instead of nested under their parents. sort is one of
+// "newest" (default), "oldest" or "top".
+func (s *BookService) GetComments(ctx context.Context, bookID string, page, pageSize int, sort string, flat bool) (*dto.CommentListResponse, error) {
+    book, err := s.bookRepo.FindByID(ctx, bookID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find book", err)
+    }
+    if book == nil {
+        return nil, utils.NewNotFoundError("book")
+    }
+
+    if page < 1 {
+        page = 1
+    }
+    if pageSize < 1 || pageSize > 100 {
+        pageSize = 20
+    }
+
+    offset := (page - 1) * pageSize
+    threads, err := s.commentRepo.FindThreadsByBookID(ctx, bookID, sort, pageSize, offset)
+    if err != nil {
+        return nil, err
+    }
+
+    threadIDs := make([]string, len(threads))
+    for i, thread := range threads {
+        threadIDs[i] = thread.ID
+    }
+
+    replies, err := s.commentRepo.FindReplies(ctx, threadIDs, sort)
+    if err != nil {
+        return nil, err
+    }
+
+    totalThreads, err := s.commentRepo.CountThreadsByBookID(ctx, bookID)
+    if err != nil {
+        return nil, err
+    }
+
+    total, err := s.commentRepo.CountByBookID(ctx, bookID)
+    if err != nil {
+        return nil, err
+    }
+
+    comments := buildCommentTree(threads, replies)
+    if flat {
+        comments = flattenCommentTree(comments)
+    }
+
+    return &dto.CommentListResponse{
+        Comments:   comments,
+        Total:      total,
+        Pagination: utils.BuildPaginationResponse(page, pageSize, totalThreads),
+    }, nil
+}
+
+func buildCommentTree(threads, replies []*models.CommentWithUser) []*dto.CommentResponse {
+    nodes := make(map[string]*dto.CommentResponse, len(threads)+len(replies))
+
+    roots := make([]*dto.CommentResponse, len(threads))
+    for i, thread := range threads {
+        node := utils.MapCommentToResponse(thread)
+        roots[i] = &node
+        nodes[thread.ID] = roots[i]
+    }
+
+    for _, reply := range replies {
+        node := utils.MapCommentToResponse(reply)
+        nodes[reply.ID] = &node
+    }
+
+    // replies are ordered oldest first, which keeps siblings in conversation order
+    for _, reply := range replies {
+        if parent, ok := nodes[*reply.ParentID]; ok {
+            parent.Replies = append(parent.Replies, nodes[reply.ID])
+            parent.ReplyCount++
+        }
+    }
+
+    return roots
+}
+
+func flattenCommentTree(nodes []*dto.CommentResponse) []*dto.CommentResponse {
+    flat := make([]*dto.CommentResponse, 0, len(nodes))
+    for _, node := range nodes {
+        replies := node.Replies
+        node.Replies = nil
+        flat = append(flat, node)
+        flat = append(flat, flattenCommentTree(replies)...)
+    }
+    return flat
+}
+
+// validateParentCategory normalizes an empty parent ID to nil and checks that the parent exists
+func (s *BookService) validateParentCategory(ctx context.Context, parentID *string) (*string, error) {
+    if parentID == nil || *parentID == "" {
+        return nil, nil
+    }
+
+    parent, err := s.categoryRepo.FindByID(ctx, *parentID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find parent category", err)
+    }
+    if parent == nil {
+        return nil, utils.NewValidationError("parent category not found")
+    }
+
+    return parentID, nil
+}
+
+func (s *BookService) CreateCategory(ctx context.Context, req *dto.CreateCategoryRequest) (*models.Category, error) {
+    parentID, err := s.validateParentCategory(ctx, req.ParentID)
+    if err != nil {
+        return nil, err
+    }
+
+    category := &models.Category{
+        Name:        req.Name,
+        Description: req.Description,
+        ParentID:    parentID,
+    }
+
+    if err := s.categoryRepo.Create(ctx, category); err != nil {
+        if errors.Is(err, repository.ErrConflict) {
+            return nil, utils.NewAlreadyExistsError("category with this name")
+        }
+        return nil, err
+    }
+
+    return category, nil
+}
+
+func (s *BookService) UpdateCategory(ctx context.Context, categoryID string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
+    category, err := s.categoryRepo.FindByID(ctx, categoryID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find category", err)
+    }
+    if category == nil {
+        return nil, utils.NewNotFoundError("category")
+    }
+
+    existing, err := s.categoryRepo.FindByName(ctx, req.Name)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to check existing category", err)
+    }
+    if existing != nil && existing.ID != categoryID {
+        return nil, utils.NewAlreadyExistsError("category with this name")
+    }
+
+    // Without parent_id the category stays where it is
+    parentID := category.ParentID
+    if req.ParentID.Set {
+        if parentID, err = s.validateParentCategory(ctx, req.ParentID.Value); err != nil {
+            return nil, err
+        }
+    }
+    if req.ParentID.Set && parentID != nil {
+        // Moving a category below itself or one of its descendants would create a cycle
+        inSubtree, err := s.categoryRepo.IsInSubtree(ctx, categoryID, *parentID)
+        if err != nil {
+            return nil, utils.NewInternalServerError("failed to check category hierarchy", err)
+        }
+        if inSubtree {
+            return nil, utils.NewValidationError("category cannot be moved under itself or its subcategories")
+        }
+    }
+
+    category.Name = req.Name
+    category.Description = req.Description
+    category.ParentID = parentID
+
+    if err := s.categoryRepo.Update(ctx, category); err != nil {
+        if errors.Is(err, repository.ErrConflict) {
+            return nil, utils.NewAlreadyExistsError("category with this name")
+        }
+        return nil, utils.NewInternalServerError("failed to update category", err)
+    }
+
+    count, err := s.bookRepo.CountByCategory(ctx, categoryID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to count category books", err)
+    }
+
+    response := utils.MapCategoryToResponse(category)
+    response.BookCount = count
+    return &response, nil
+}
+
+func (s *BookService) GetAllCategories(ctx context.Context) ([]*models.CategoryWithBookCount, error) {
+    return s.categoryRepo.FindAllWithBookCount(ctx)
+}
+
+// GetCategoryTree returns all categories nested under their parents
+func (s *BookService) GetCategoryTree(ctx context.Context) ([]*dto.CategoryTreeNode, error) {
+    categories, err := s.categoryRepo.FindAllWithBookCount(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    return buildCategoryTree(categories), nil
+}
+
+func buildCategoryTree(categories []*models.CategoryWithBookCount) []*dto.CategoryTreeNode {
+    nodes := make(map[string]*dto.CategoryTreeNode, len(categories))
+    for _, cat := range categories {
+        response := utils.MapCategoryToResponse(&cat.Category)
+        response.BookCount = cat.BookCount
+        nodes[cat.ID] = &dto.CategoryTreeNode{
+            CategoryResponse: response,
+            Children:         []*dto.CategoryTreeNode{},
+        }
+    }
+
+    // categories are sorted by name, so children keep that order
+    roots := []*dto.CategoryTreeNode{}
+    for _, cat := range categories {
+        node := nodes[cat.ID]
+        if cat.ParentID != nil {
+            if parent, ok := nodes[*cat.ParentID]; ok {
+                parent.Children = append(parent.Children, node)
+                continue
+            }
+        }
+        roots = append(roots, node)
+    }
+
+    for _, root := range roots {
+        sumTreeBookCounts(root)
+    }
+
+    return roots
+}
+
+func sumTreeBookCounts(node *dto.CategoryTreeNode) int {
+    node.TotalBookCount = node.BookCount
+    for _, child := range node.Children {
+        node.TotalBookCount += sumTreeBookCounts(child)
+    }
+    return node.TotalBookCount
+}
+
+func (s *BookService) DeleteCategory(ctx context.Context, categoryID string) error {
+    category, err := s.categoryRepo.FindByID(ctx, categoryID)
+    if err != nil {
+        return utils.NewInternalServerError("failed to find category", err)
+    }
+    if category == nil {
+        return utils.NewNotFoundError("category")
+    }
+
+    children, err := s.categoryRepo.CountChildren(ctx, categoryID)
+    if err != nil {
+        return utils.NewInternalServerError("failed to count subcategories", err)
+    }
+    if children > 0 {
+        return utils.NewConflictError("cannot delete category with subcategories")
+    }
+
+    count, err := s.bookRepo.CountByCategoryTree(ctx, categoryID)
+    if err != nil {
+        return utils.NewInternalServerError("failed to count category books", err)
+    }
+    if count > 0 {
+        return utils.NewConflictError("cannot delete category with existing books")
+    }
+
+    // Deleting the category cascades to its books, so books in the trash
+    // would be lost for good without their files being cleaned up
+    deleted, err := s.bookRepo.CountDeletedByCategory(ctx, categoryID)
+    if err != nil {
+        return utils.NewInternalServerError("failed to count deleted category books", err)
+    }
+    if deleted > 0 {
+        return utils.NewConflictError("cannot delete category with deleted books that have not been purged yet")
+    }
+
+    if err := s.categoryRepo.Delete(ctx, categoryID); err != nil {
+        return utils.NewInternalServerError("failed to delete category", err)
+    }
+    return nil
+}
+
+// validateAuthors checks that the authors of a book exist, dropping empty
+// and repeated IDs while keeping the order of the rest
+func (s *BookService) validateAuthors(ctx context.Context, authorIDs []string) ([]string, error) {
+    seen := make(map[string]bool, len(authorIDs))
+    ids := make([]string, 0, len(authorIDs))
+    for _, id := range authorIDs {
+        id = strings.TrimSpace(id)
+        if id == "" || seen[id] {
+            continue
+        }
+        seen[id] = true
+
+        author, err := s.authorRepo.FindByID(ctx, id)
+        if err != nil {
+            return nil, utils.NewInternalServerError("failed to find author", err)
+        }
+        if author == nil {
+            return nil, utils.NewValidationError("author " + id + " not found")
+        }
+        ids = append(ids, id)
+    }
+    return ids, nil
+}
+
+func (s *BookService) CreateAuthor(ctx context.Context, req *dto.CreateAuthorRequest) (*models.Author, error) {
+    author := &models.Author{
+        Name: strings.TrimSpace(req.Name),
+        Bio:  strings.TrimSpace(req.Bio),
+    }
+    if author.Name == "" {
+        return nil, utils.NewValidationError("name is required")
+    }
+
+    if err := s.authorRepo.Create(ctx, author); err != nil {
+        if errors.Is(err, repository.ErrConflict) {
+            return nil, utils.NewAlreadyExistsError("author with this name")
+        }
+        return nil, utils.NewInternalServerError("failed to create author", err)
+    }
+
+    return author, nil
+}
+
+// UpdateAuthor renames the author, which renames it in its books and their
+// search index as well
+func (s *BookService) UpdateAuthor(ctx context.Context, authorID string, req *dto.UpdateAuthorRequest) (*dto.AuthorResponse, error) {
+    author, err := s.authorRepo.FindByID(ctx, authorID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find author", err)
+    }
+    if author == nil {
+        return nil, utils.NewNotFoundError("author")
+    }
+
+    author.Name = strings.TrimSpace(req.Name)
+    author.Bio = strings.TrimSpace(req.Bio)
+    if author.Name == "" {
+        return nil, utils.NewValidationError("name is required")
+    }
+
+    err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        return repos.Authors.Update(ctx, author)
+    })
+    if err != nil {
+        if errors.Is(err, repository.ErrConflict) {
+            return nil, utils.NewAlreadyExistsError("author with this name")
+        }
+        return nil, utils.NewInternalServerError("failed to update author", err)
+    }
+
+    return s.authorResponse(ctx, author)
+}
+
+func (s *BookService) GetAuthor(ctx context.Context, authorID string) (*dto.AuthorResponse, error) {
+    author, err := s.authorRepo.FindByID(ctx, authorID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find author", err)
+    }
+    if author == nil {
+        return nil, utils.NewNotFoundError("author")
+    }
+
+    return s.authorResponse(ctx, author)
+}
+
+func (s *BookService) authorResponse(ctx context.Context, author *models.Author) (*dto.AuthorResponse, error) {
+    count, err := s.bookRepo.CountByAuthor(ctx, author.ID)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to count author books", err)
+    }
+
+    response := utils.MapAuthorToResponse(author)
+    response.BookCount = count
+    return &response, nil
+}
+
+func (s *BookService) GetAllAuthors(ctx context.Context, page, pageSize int) ([]*models.AuthorWithBookCount, int, error) {
+    if page < 1 {
+        page = 1
+    }
+    if pageSize < 1 || pageSize > 100 {
+        pageSize = 20
+    }
+
+    authors, err := s.authorRepo.FindAll(ctx, pageSize, (page-1)*pageSize)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    total, err := s.authorRepo.CountAll(ctx)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return authors, total, nil
+}
+
+// DeleteAuthor removes the author from its books and deletes it. The books
+// themselves are kept.
+func (s *BookService) DeleteAuthor(ctx context.Context, authorID string) error {
+    err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+        return repos.Authors.Delete(ctx, authorID)
+    })
+    if errors.Is(err, sql.ErrNoRows) {
+        return utils.NewNotFoundError("author")
+    }
+    if err != nil {
+        return utils.NewInternalServerError("failed to delete author", err)
+    }
+    return nil
+}
+
+// GetBooksByAuthor lists the books of an author, newest first unless sortBy is given
+func (s *BookService) GetBooksByAuthor(ctx context.Context, authorID string, page, pageSize int, sortBy, order string) ([]*models.BookWithCategory, int, error) {
+    if page < 1 {
+        page = 1
+    }
+    if pageSize < 1 || pageSize > 100 {
+        pageSize = 20
+    }
+
+    author, err := s.authorRepo.FindByID(ctx, authorID)
+    if err != nil {
+        return nil, 0, utils.NewInternalServerError("failed to find author", err)
+    }
+    if author == nil {
+        return nil, 0, utils.NewNotFoundError("author")
+    }
+
+    offset := (page - 1) * pageSize
+    books, err := s.bookRepo.FindByAuthorPaginated(ctx, authorID, pageSize, offset, repository.BookSort{Field: sortBy, Order: order})
+    if err != nil {
+        return nil, 0, err
+    }
+
+    total, err := s.bookRepo.CountByAuthor(ctx, authorID)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    return books, total, nil
+}
+
+// Limits of the free-form tags of books
+const (
+    maxTagLength = 50
+    maxBookTags  = 20
+)
+
+// normalizeTags turns user input into tag names: every entry may hold several
+// comma separated tags, which are trimmed, lower cased and have their inner
+// whitespace collapsed. Empty and repeated tags are dropped.
+func normalizeTags(raw []string) ([]string, error) {
+    seen := make(map[string]bool)
+    tags := []string{}
+    for _, entry := range raw {
+        for _, tag := range strings.Split(entry, ",") {
+            tag = normalizeTag(tag)
+            if tag == "" || seen[tag] {
+                continue
+            }
+            if len([]rune(tag)) > maxTagLength {
+                return nil, utils.NewValidationError(fmt.Sprintf("tag %q is longer than %d characters", tag, maxTagLength))
+            }
+            seen[tag] = true
+            tags = append(tags, tag)
+        }
+    }
+    if len(tags) > maxBookTags {
+        return nil, utils.NewValidationError(fmt.Sprintf("at most %d tags are allowed", maxBookTags))
+    }
+    return tags, nil
+}
+
+func normalizeTag(tag string) string {
+    return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// GetTags returns the tags in use starting with prefix, the most used first,
+// for autocompleting tags. An empty prefix returns the most used tags.
+func (s *BookService) GetTags(ctx context.Context, prefix string, limit int) ([]*models.TagWithBookCount, error) {
+    if limit < 1 || limit > 50 {
+        limit = 10
+    }
+
+    tags, err := s.tagRepo.FindByPrefix(ctx, normalizeTag(prefix), limit)
+    if err != nil {
+        return nil, utils.NewInternalServerError("failed to find tags", err)
+    }
+    return tags, nil
+}
@@ -0,0 +1,1171 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"library-project/internal/cover"
+	"library-project/internal/dto"
+	"library-project/internal/models"
+	"library-project/internal/pdfmeta"
+	"library-project/internal/repository"
+	"library-project/internal/repository/memory"
+	"library-project/internal/storage"
+	"library-project/internal/utils"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type bookTestEnv struct {
+	store   *memory.Store
+	books   *BookService
+	uploads string
+	owner   string
+	member  string
+}
+
+func newBookTestEnv(t *testing.T) *bookTestEnv {
+	t.Helper()
+
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+
+	owner := &models.User{Email: "owner@example.com", FirstName: "Olive", LastName: "Owner", Role: models.RoleOwner}
+	member := &models.User{Email: "member@example.com", FirstName: "Max", LastName: "Member", Role: models.RoleMember}
+	for _, user := range []*models.User{owner, member} {
+		if err := users.Create(t.Context(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := testConfig()
+	cfg.Upload.Path = t.TempDir()
+	files, err := storage.NewLocal(cfg.Upload.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	books := NewBookService(
+		memory.NewBookRepository(store),
+		memory.NewCategoryRepository(store),
+		memory.NewAuthorRepository(store),
+		memory.NewTagRepository(store),
+		memory.NewLikeRepository(store),
+		memory.NewSavedBookRepository(store),
+		memory.NewCommentRepository(store),
+		memory.NewDownloadRepository(store),
+		memory.NewCommentLikeRepository(store),
+		memory.NewReadingRepository(store),
+		memory.NewBookFileRepository(store),
+		users,
+		memory.NewUnitOfWork(store),
+		files,
+		cfg,
+	)
+
+	return &bookTestEnv{store: store, books: books, uploads: cfg.Upload.Path, owner: owner.ID, member: member.ID}
+}
+
+func (e *bookTestEnv) createCategory(t *testing.T, name string, parentID *string) *models.Category {
+	t.Helper()
+
+	category, err := e.books.CreateCategory(t.Context(), &dto.CreateCategoryRequest{Name: name, ParentID: parentID})
+	if err != nil {
+		t.Fatalf("create category %s: %v", name, err)
+	}
+	return category
+}
+
+func (e *bookTestEnv) createBook(t *testing.T, title, categoryID string) *models.Book {
+	t.Helper()
+
+	book, err := e.books.CreateBook(t.Context(), &dto.CreateBookRequest{
+		Title:       title,
+		Description: "About " + title,
+		CategoryID:  categoryID,
+	}, &models.BookFile{PDFFile: title + ".pdf", Format: "pdf"}, nil, "", e.owner)
+	if err != nil {
+		t.Fatalf("create book %s: %v", title, err)
+	}
+	return book
+}
+
+func TestBookCRUD(t *testing.T) {
+	env := newBookTestEnv(t)
+	fiction := env.createCategory(t, "Fiction", nil)
+	poetry := env.createCategory(t, "Poetry", nil)
+
+	book := env.createBook(t, "Dune", fiction.ID)
+
+	found, err := env.books.GetBook(t.Context(), book.ID)
+	if err != nil || found == nil {
+		t.Fatalf("get book: %v", err)
+	}
+	if found.CategoryName != "Fiction" {
+		t.Errorf("expected category name Fiction, got %q", found.CategoryName)
+	}
+
+	update := &dto.UpdateBookRequest{Title: "Dune Messiah", Description: "Sequel", CategoryID: poetry.ID}
+	assertStatus(t, env.books.UpdateBook(t.Context(), book.ID, update, "", env.member), http.StatusForbidden)
+	if err := env.books.UpdateBook(t.Context(), book.ID, update, "", env.owner); err != nil {
+		t.Fatalf("update book: %v", err)
+	}
+
+	found, _ = env.books.GetBook(t.Context(), book.ID)
+	if found.Title != "Dune Messiah" || found.CategoryID != poetry.ID {
+		t.Errorf("update not applied: %+v", found.Book)
+	}
+
+	assertStatus(t, env.books.DeleteBook(t.Context(), book.ID, env.member), http.StatusForbidden)
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatalf("delete book: %v", err)
+	}
+	if found, _ := env.books.GetBook(t.Context(), book.ID); found != nil {
+		t.Error("expected book to be deleted")
+	}
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err == nil || err.Error() != "book not found" {
+		t.Errorf("expected book not found, got %v", err)
+	}
+}
+
+func TestRestoreDeletedBook(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Science", nil)
+	book := env.createBook(t, "Cosmos", category.ID)
+
+	if err := env.books.SaveBook(t.Context(), env.member, book.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatalf("delete book: %v", err)
+	}
+
+	if books, _ := env.books.GetAllBooks(t.Context()); len(books) != 0 {
+		t.Errorf("expected deleted book to be hidden, got %v", titles(books))
+	}
+	if saved, _ := env.books.GetSavedBooks(t.Context(), env.member, "", ""); len(saved) != 0 {
+		t.Errorf("expected deleted book to be hidden from saved books, got %v", titles(saved))
+	}
+	if err := env.books.SaveBook(t.Context(), env.member, book.ID); err == nil || err.Error() != "book not found" {
+		t.Errorf("expected book not found when saving a deleted book, got %v", err)
+	}
+
+	_, err := env.books.RestoreBook(t.Context(), book.ID, env.member)
+	assertStatus(t, err, http.StatusForbidden)
+
+	restored, err := env.books.RestoreBook(t.Context(), book.ID, env.owner)
+	if err != nil {
+		t.Fatalf("restore book: %v", err)
+	}
+	if restored.ID != book.ID || restored.SaveCount != 1 {
+		t.Errorf("unexpected restored book: %+v", restored.Book)
+	}
+
+	_, err = env.books.RestoreBook(t.Context(), book.ID, env.owner)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestPurgeDeletedBooks(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Drama", nil)
+	kept := env.createBook(t, "Hamlet", category.ID)
+	deleted := env.createBook(t, "Macbeth", category.ID)
+	for _, book := range []*models.Book{kept, deleted} {
+		if err := os.WriteFile(filepath.Join(env.uploads, book.PDFFile), []byte("%PDF-1.4"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := env.books.DeleteBook(t.Context(), deleted.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still within the retention period
+	purged, err := env.books.PurgeDeletedBooks(t.Context(), time.Hour)
+	if err != nil || purged != 0 {
+		t.Fatalf("expected nothing to be purged, got %d (%v)", purged, err)
+	}
+
+	purged, err = env.books.PurgeDeletedBooks(t.Context(), 0)
+	if err != nil || purged != 1 {
+		t.Fatalf("expected one purged book, got %d (%v)", purged, err)
+	}
+	if _, err := os.Stat(filepath.Join(env.uploads, deleted.PDFFile)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the file of the purged book to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(env.uploads, kept.PDFFile)); err != nil {
+		t.Errorf("expected the file of the kept book to remain: %v", err)
+	}
+
+	_, err = env.books.RestoreBook(t.Context(), deleted.ID, env.owner)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestDeleteCategoryWithDeletedBooks(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Poetry", nil)
+	book := env.createBook(t, "Odes", category.ID)
+
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	// The book is only in the trash, deleting the category would cascade to it
+	assertStatus(t, env.books.DeleteCategory(t.Context(), category.ID), http.StatusConflict)
+	if _, err := env.books.RestoreBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatalf("expected the book to be restorable: %v", err)
+	}
+
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.books.PurgeDeletedBooks(t.Context(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.books.DeleteCategory(t.Context(), category.ID); err != nil {
+		t.Errorf("expected the category to be deleted once its books are purged: %v", err)
+	}
+	assertStatus(t, env.books.DeleteCategory(t.Context(), category.ID), http.StatusNotFound)
+}
+
+func TestBookFileVersions(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Art", nil)
+	book := env.createBook(t, "Ways of Seeing", category.ID)
+	for _, file := range []string{book.PDFFile, "rescan.epub"} {
+		if err := os.WriteFile(filepath.Join(env.uploads, file), []byte("%PDF-1.4"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := env.books.ReplaceBookFile(t.Context(), book.ID, env.member, &models.BookFile{PDFFile: "rescan.epub", Format: "epub"})
+	assertStatus(t, err, http.StatusForbidden)
+
+	replaced, err := env.books.ReplaceBookFile(t.Context(), book.ID, env.owner, &models.BookFile{PDFFile: "rescan.epub", Format: "epub"})
+	if err != nil {
+		t.Fatalf("replace file: %v", err)
+	}
+	if replaced.Version != 2 {
+		t.Errorf("expected version 2, got %d", replaced.Version)
+	}
+	current, _ := env.books.GetBook(t.Context(), book.ID)
+	if current.PDFFile != "rescan.epub" || current.Format != "epub" {
+		t.Errorf("expected the book to use the new EPUB file, got %s (%s)", current.PDFFile, current.Format)
+	}
+
+	restored, err := env.books.RollbackBookFile(t.Context(), book.ID, 1, env.owner)
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if restored.Version != 3 || restored.RestoredFrom == nil || *restored.RestoredFrom != 1 || restored.PDFFile != book.PDFFile {
+		t.Errorf("unexpected rollback version %+v", restored)
+	}
+	current, _ = env.books.GetBook(t.Context(), book.ID)
+	if current.PDFFile != book.PDFFile || current.Format != "pdf" {
+		t.Errorf("expected the book to use the original PDF again, got %s (%s)", current.PDFFile, current.Format)
+	}
+
+	_, err = env.books.RollbackBookFile(t.Context(), book.ID, 3, env.owner)
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = env.books.RollbackBookFile(t.Context(), book.ID, 9, env.owner)
+	assertStatus(t, err, http.StatusNotFound)
+
+	files, err := env.books.GetBookFiles(t.Context(), book.ID, env.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	for _, file := range files {
+		versions = append(versions, file.Version)
+	}
+	if !slices.Equal(versions, []int{3, 2, 1}) {
+		t.Errorf("expected versions newest first, got %v", versions)
+	}
+
+	// Purging removes the files of every version
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := env.books.PurgeDeletedBooks(t.Context(), 0); err != nil || purged != 1 {
+		t.Fatalf("expected one purged book, got %d (%v)", purged, err)
+	}
+	for _, file := range []string{book.PDFFile, "rescan.epub"} {
+		if _, err := os.Stat(filepath.Join(env.uploads, file)); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected %s to be removed, got %v", file, err)
+		}
+	}
+}
+
+func TestFindOrphanedFiles(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Essays", nil)
+	book := env.createBook(t, "Walden", category.ID)
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-2 * orphanGracePeriod)
+	for _, name := range []string{book.PDFFile, "orphan.pdf", "uploading.pdf"} {
+		path := filepath.Join(env.uploads, name)
+		if err := os.WriteFile(path, []byte("%PDF-1.4"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "uploading.pdf" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// The deleted book still owns its file until it is purged, and the
+	// recent upload may not have been inserted yet
+	orphans, err := env.books.FindOrphanedFiles(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(orphans, []string{"orphan.pdf"}) {
+		t.Errorf("expected only orphan.pdf to be reported, got %v", orphans)
+	}
+}
+
+func TestBookCovers(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Poetry", nil)
+
+	writeCover := func(base string) {
+		t.Helper()
+		for _, key := range cover.Keys(base) {
+			path := filepath.Join(env.uploads, filepath.FromSlash(key))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("jpeg"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeCover("covers/first")
+	writeCover("covers/second")
+
+	book, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{Title: "Leaves of Grass", CategoryID: category.ID},
+		&models.BookFile{PDFFile: "leaves.pdf", Format: "pdf"}, nil, "covers/first", env.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := &dto.UpdateBookRequest{Title: book.Title, Description: "Poems", CategoryID: category.ID}
+	if err := env.books.UpdateBook(t.Context(), book.ID, update, "covers/second", env.owner); err != nil {
+		t.Fatal(err)
+	}
+	found, _ := env.books.GetBook(t.Context(), book.ID)
+	if found.CoverImage != "covers/second" {
+		t.Errorf("expected the new cover, got %q", found.CoverImage)
+	}
+	for _, key := range cover.Keys("covers/first") {
+		if _, err := os.Stat(filepath.Join(env.uploads, filepath.FromSlash(key))); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected the replaced thumbnail %s to be removed, got %v", key, err)
+		}
+	}
+
+	// Updating without a cover keeps the current one
+	if err := env.books.UpdateBook(t.Context(), book.ID, update, "", env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := env.books.GetBook(t.Context(), book.ID); found.CoverImage != "covers/second" {
+		t.Errorf("expected the cover to be kept, got %q", found.CoverImage)
+	}
+
+	old := time.Now().Add(-2 * orphanGracePeriod)
+	for _, key := range cover.Keys("covers/second") {
+		if err := os.Chtimes(filepath.Join(env.uploads, filepath.FromSlash(key)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orphans, err := env.books.FindOrphanedFiles(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("expected the thumbnails of the cover not to be orphans, got %v", orphans)
+	}
+
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.books.PurgeDeletedBooks(t.Context(), 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range cover.Keys("covers/second") {
+		if _, err := os.Stat(filepath.Join(env.uploads, filepath.FromSlash(key))); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected the thumbnail %s of the purged book to be removed, got %v", key, err)
+		}
+	}
+}
+
+func TestFileHashIsComputedOnce(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Biography", nil)
+	created := env.createBook(t, "Einstein", category.ID)
+	if err := os.WriteFile(filepath.Join(env.uploads, created.PDFFile), []byte("%PDF-1.4"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	book, _ := env.books.GetBook(t.Context(), created.ID)
+	hash, err := env.books.FileHash(t.Context(), book)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sha256("%PDF-1.4")
+	const want = "e16fa5d9b51928755db85b917f0297babaf22c7a47e97d9212adab56e61ba04e"
+	if hash != want {
+		t.Fatalf("expected hash %s, got %s", want, hash)
+	}
+
+	stored, _ := env.books.GetBook(t.Context(), created.ID)
+	if stored.FileHash != hash {
+		t.Errorf("expected the hash to be stored, got %q", stored.FileHash)
+	}
+}
+
+func TestReadingProgressAndViews(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Travel", nil)
+	book := env.createBook(t, "Roughing It", category.ID)
+
+	_, err := env.books.GetReadingProgress(t.Context(), env.member, book.ID)
+	assertStatus(t, err, http.StatusNotFound)
+
+	for _, page := range []int{12, 7} {
+		if _, err := env.books.SaveReadingProgress(t.Context(), env.member, book.ID, page); err != nil {
+			t.Fatalf("save progress: %v", err)
+		}
+	}
+	progress, err := env.books.GetReadingProgress(t.Context(), env.member, book.ID)
+	if err != nil || progress.Page != 7 {
+		t.Fatalf("expected the latest page 7, got %+v (%v)", progress, err)
+	}
+	if _, err := env.books.GetReadingProgress(t.Context(), env.owner, book.ID); err == nil {
+		t.Error("expected progress to be kept per user")
+	}
+	_, err = env.books.SaveReadingProgress(t.Context(), env.member, "missing", 1)
+	assertStatus(t, err, http.StatusNotFound)
+
+	for range 2 {
+		if err := env.books.RecordView(t.Context(), env.member, book.ID, "127.0.0.1", "test"); err != nil {
+			t.Fatalf("record view: %v", err)
+		}
+	}
+	if err := env.books.RecordDownload(t.Context(), env.member, book.ID, "127.0.0.1", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := NewStatisticsService(memory.NewStatisticsRepository(env.store), memory.NewBookRepository(env.store))
+	statistics, err := stats.GetBookStatistics(t.Context(), book.ID, env.owner, &dto.StatisticsRequest{Days: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statistics.Views != 2 || statistics.Downloads != 1 {
+		t.Errorf("expected 2 views and 1 download, got %d and %d", statistics.Views, statistics.Downloads)
+	}
+	if len(statistics.Timeline) != 1 || statistics.Timeline[0].Views != 2 {
+		t.Errorf("expected the views in today's bucket, got %+v", statistics.Timeline)
+	}
+}
+
+func TestDownloadLinks(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Cooking", nil)
+	book := env.createBook(t, "Salt Fat Acid Heat", category.ID)
+
+	token, expiresAt, err := env.books.CreateDownloadLink(t.Context(), book.ID, env.member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(expiresAt); until <= 0 || until > 5*time.Minute {
+		t.Errorf("unexpected expiry %s", expiresAt)
+	}
+
+	claims, err := env.books.ResolveDownloadLink(t.Context(), token)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if claims.UserID != env.member || claims.BookID != book.ID {
+		t.Errorf("link bound to user %s and book %s", claims.UserID, claims.BookID)
+	}
+
+	_, _, err = env.books.CreateDownloadLink(t.Context(), "missing", env.member)
+	assertStatus(t, err, http.StatusNotFound)
+
+	secret := testConfig().JWT.Secret
+	expired, _ := utils.GenerateDownloadToken(env.member, book.ID, secret, time.Now().Add(-time.Minute))
+	accessToken, _ := utils.GenerateToken(env.member, "member@example.com", string(models.RoleMember), secret, 1)
+	for name, token := range map[string]string{
+		"expired":      expired,
+		"tampered":     token[:len(token)-2] + "xx",
+		"access token": accessToken,
+	} {
+		_, err := env.books.ResolveDownloadLink(t.Context(), token)
+		if err == nil {
+			t.Errorf("expected the %s to be rejected", name)
+			continue
+		}
+		assertStatus(t, err, http.StatusForbidden)
+	}
+	if _, err := utils.ValidateToken(token, secret); err == nil {
+		t.Error("expected the download token to be rejected as an access token")
+	}
+
+	// Logging out invalidates the links issued before
+	if err := memory.NewUserRepository(env.store).InvalidateTokens(t.Context(), env.member); err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.books.ResolveDownloadLink(t.Context(), token)
+	assertStatus(t, err, http.StatusForbidden)
+}
+
+func TestCreateBookRequiresCategory(t *testing.T) {
+	env := newBookTestEnv(t)
+
+	_, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{Title: "Orphan", CategoryID: "missing"}, &models.BookFile{PDFFile: "orphan.pdf"}, nil, "", env.owner)
+	if err == nil || err.Error() != "category not found" {
+		t.Fatalf("expected category not found, got %v", err)
+	}
+}
+
+func TestCreateBookFromPDFMetadata(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Computing", nil)
+
+	created := time.Date(2017, 7, 19, 8, 30, 0, 0, time.UTC)
+	meta := &pdfmeta.Metadata{
+		Title:     "Concurrency in Go",
+		Author:    "Katherine Cox-Buday",
+		Subject:   "Tools and techniques for developers",
+		Keywords:  "go, concurrency",
+		PageCount: 238,
+		CreatedAt: &created,
+	}
+	book, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{CategoryID: category.ID, Keywords: "golang"},
+		&models.BookFile{PDFFile: "concurrency.pdf", Format: "pdf", PageCount: meta.PageCount}, meta, "", env.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := env.books.GetBook(t.Context(), book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Title != meta.Title || found.Description != meta.Subject || found.Subject != meta.Subject || found.Author != meta.Author {
+		t.Errorf("expected empty fields to be filled in from the metadata, got %+v", found.Book)
+	}
+	if found.Keywords != "golang" {
+		t.Errorf("expected the keywords the owner typed to win, got %q", found.Keywords)
+	}
+	if found.PageCount != 238 || found.DocumentCreatedAt == nil || !found.DocumentCreatedAt.Equal(created) {
+		t.Errorf("unexpected page count %d and creation date %v", found.PageCount, found.DocumentCreatedAt)
+	}
+
+	// Without a title in the form or in the file there is nothing to call the book
+	_, err = env.books.CreateBook(t.Context(), &dto.CreateBookRequest{CategoryID: category.ID, Description: "Untitled"},
+		&models.BookFile{PDFFile: "untitled.pdf", Format: "pdf"}, &pdfmeta.Metadata{PageCount: 3}, "", env.owner)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Page count filter; books whose page count is unknown never match it
+	short, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{Title: "Pamphlet", CategoryID: category.ID},
+		&models.BookFile{PDFFile: "pamphlet.pdf", Format: "pdf", PageCount: 12}, &pdfmeta.Metadata{Title: "Ignored", PageCount: 12}, "", env.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if short.Title != "Pamphlet" {
+		t.Errorf("expected the title the owner typed to win, got %q", short.Title)
+	}
+	env.createBook(t, "Unknown length", category.ID)
+
+	for _, tc := range []struct {
+		filter dto.BookFilterRequest
+		want   []string
+	}{
+		{dto.BookFilterRequest{MinPages: 100}, []string{"Concurrency in Go"}},
+		{dto.BookFilterRequest{MaxPages: 100}, []string{"Pamphlet"}},
+		{dto.BookFilterRequest{MinPages: 12, MaxPages: 238, PaginationRequest: dto.PaginationRequest{SortBy: "page_count", Order: "asc"}}, []string{"Pamphlet", "Concurrency in Go"}},
+		{dto.BookFilterRequest{MinPages: 13, MaxPages: 237}, nil},
+	} {
+		results, total, err := env.books.SearchBooks(t.Context(), &tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != len(tc.want) || !slices.Equal(titles(results), tc.want) {
+			t.Errorf("pages %d-%d: expected %v, got %v", tc.filter.MinPages, tc.filter.MaxPages, tc.want, titles(results))
+		}
+	}
+}
+
+func TestPaginationAndSearch(t *testing.T) {
+	env := newBookTestEnv(t)
+	science := env.createCategory(t, "Science", nil)
+	physics := env.createCategory(t, "Physics", &science.ID)
+
+	env.createBook(t, "Cosmos", science.ID)
+	env.createBook(t, "Quantum Physics", physics.ID)
+	env.createBook(t, "Relativity", physics.ID)
+
+	page, total, err := env.books.GetAllBooksPaginated(t.Context(), 1, 2, "title", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(page) != 2 || page[0].Title != "Cosmos" || page[1].Title != "Quantum Physics" {
+		t.Errorf("unexpected first page: total=%d books=%v", total, titles(page))
+	}
+
+	page, total, err = env.books.GetBooksByCategoryPaginated(t.Context(), science.ID, 1, 20, "", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(page) != 3 {
+		t.Errorf("expected subtree listing to include nested categories, got total=%d", total)
+	}
+
+	results, total, err := env.books.SearchBooks(t.Context(), &dto.BookFilterRequest{Search: "quant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || results[0].Title != "Quantum Physics" {
+		t.Errorf("unexpected search results: %v", titles(results))
+	}
+}
+
+func TestAuthors(t *testing.T) {
+	env := newBookTestEnv(t)
+	fiction := env.createCategory(t, "Fiction", nil)
+
+	pratchett, err := env.books.CreateAuthor(t.Context(), &dto.CreateAuthorRequest{Name: "Terry Pratchett"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gaiman, err := env.books.CreateAuthor(t.Context(), &dto.CreateAuthorRequest{Name: "Neil Gaiman"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.books.CreateAuthor(t.Context(), &dto.CreateAuthorRequest{Name: "Neil Gaiman"})
+	assertStatus(t, err, http.StatusConflict)
+
+	newBook := func(title string, authorIDs ...string) (*models.Book, error) {
+		return env.books.CreateBook(t.Context(), &dto.CreateBookRequest{
+			Title:      title,
+			CategoryID: fiction.ID,
+			AuthorIDs:  authorIDs,
+		}, &models.BookFile{PDFFile: title + ".pdf", Format: "pdf"}, nil, "", env.owner)
+	}
+
+	_, err = newBook("Unknown", "missing")
+	assertStatus(t, err, http.StatusBadRequest)
+
+	omens, err := newBook("Good Omens", pratchett.ID, gaiman.ID, pratchett.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newBook("Mort", pratchett.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	found, _ := env.books.GetBook(t.Context(), omens.ID)
+	if len(found.Authors) != 2 || found.Authors[0].Name != "Terry Pratchett" || found.Authors[1].Name != "Neil Gaiman" {
+		t.Fatalf("expected the authors in order without repeats, got %+v", found.Authors)
+	}
+
+	books, total, err := env.books.GetBooksByAuthor(t.Context(), pratchett.ID, 1, 1, "title", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || !slices.Equal(titles(books), []string{"Good Omens"}) {
+		t.Errorf("unexpected books of the author: total=%d books=%v", total, titles(books))
+	}
+	_, _, err = env.books.GetBooksByAuthor(t.Context(), "missing", 1, 20, "", "")
+	assertStatus(t, err, http.StatusNotFound)
+
+	results, _, err := env.books.SearchBooks(t.Context(), &dto.BookFilterRequest{Search: "gaim"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(titles(results), []string{"Good Omens"}) {
+		t.Errorf("expected search to match author names, got %v", titles(results))
+	}
+
+	// Omitting the authors keeps them, an empty list removes them
+	update := &dto.UpdateBookRequest{Title: "Good Omens", Description: "Apocalypse", CategoryID: fiction.ID}
+	if err := env.books.UpdateBook(t.Context(), omens.ID, update, "", env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := env.books.GetBook(t.Context(), omens.ID); len(found.Authors) != 2 {
+		t.Errorf("expected the authors to be kept, got %+v", found.Authors)
+	}
+	update.AuthorIDs = &[]string{gaiman.ID}
+	if err := env.books.UpdateBook(t.Context(), omens.ID, update, "", env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := env.books.GetBook(t.Context(), omens.ID); len(found.Authors) != 1 || found.Authors[0].ID != gaiman.ID {
+		t.Errorf("expected the authors to be replaced, got %+v", found.Authors)
+	}
+
+	authors, total, err := env.books.GetAllAuthors(t.Context(), 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || authors[0].Name != "Neil Gaiman" || authors[0].BookCount != 1 || authors[1].BookCount != 1 {
+		t.Errorf("unexpected authors: total=%d %+v", total, authors)
+	}
+
+	if err := env.books.DeleteAuthor(t.Context(), gaiman.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, env.books.DeleteAuthor(t.Context(), gaiman.ID), http.StatusNotFound)
+	found, _ = env.books.GetBook(t.Context(), omens.ID)
+	if found == nil || len(found.Authors) != 0 {
+		t.Errorf("expected the book to be kept without authors, got %+v", found)
+	}
+}
+
+func TestCreateBookLinksFreeTextAuthor(t *testing.T) {
+	env := newBookTestEnv(t)
+	fiction := env.createCategory(t, "Fiction", nil)
+
+	pratchett, err := env.books.CreateAuthor(t.Context(), &dto.CreateAuthorRequest{Name: "Terry Pratchett"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newBook := func(title, author string, authorIDs ...string) *models.Book {
+		t.Helper()
+		book, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{
+			Title:      title,
+			Author:     author,
+			CategoryID: fiction.ID,
+			AuthorIDs:  authorIDs,
+		}, &models.BookFile{PDFFile: title + ".pdf", Format: "pdf"}, nil, "", env.owner)
+		if err != nil {
+			t.Fatalf("create book %s: %v", title, err)
+		}
+		return book
+	}
+
+	mort := newBook("Mort", "terry pratchett")
+	emma := newBook("Emma", "Jane Austen")
+	persuasion := newBook("Persuasion", "Jane Austen")
+	omens := newBook("Good Omens", "Pratchett and Gaiman", pratchett.ID)
+
+	found, _ := env.books.GetBook(t.Context(), mort.ID)
+	if len(found.Authors) != 1 || found.Authors[0].ID != pratchett.ID {
+		t.Errorf("expected the existing author to be reused ignoring case, got %+v", found.Authors)
+	}
+	first, _ := env.books.GetBook(t.Context(), emma.ID)
+	second, _ := env.books.GetBook(t.Context(), persuasion.ID)
+	if len(first.Authors) != 1 || first.Authors[0].Name != "Jane Austen" ||
+		len(second.Authors) != 1 || second.Authors[0].ID != first.Authors[0].ID {
+		t.Errorf("expected one new author for both books, got %+v and %+v", first.Authors, second.Authors)
+	}
+	found, _ = env.books.GetBook(t.Context(), omens.ID)
+	if len(found.Authors) != 1 || found.Authors[0].ID != pratchett.ID {
+		t.Errorf("expected the chosen authors to win over the free-text author, got %+v", found.Authors)
+	}
+
+	books, _, err := env.books.GetBooksByAuthor(t.Context(), pratchett.ID, 1, 20, "title", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(titles(books), []string{"Good Omens", "Mort"}) {
+		t.Errorf("unexpected books of the author: %v", titles(books))
+	}
+
+	// The free-text author is searchable even when other authors were chosen
+	results, _, err := env.books.SearchBooks(t.Context(), &dto.BookFilterRequest{Search: "gaiman"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(titles(results), []string{"Good Omens"}) {
+		t.Errorf("expected search to match the free-text author, got %v", titles(results))
+	}
+}
+
+func TestTags(t *testing.T) {
+	env := newBookTestEnv(t)
+	science := env.createCategory(t, "Science", nil)
+	history := env.createCategory(t, "History", nil)
+
+	newBook := func(title, categoryID string, tags ...string) *models.Book {
+		t.Helper()
+		book, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{
+			Title:      title,
+			CategoryID: categoryID,
+			Tags:       tags,
+		}, &models.BookFile{PDFFile: title + ".pdf", Format: "pdf"}, nil, "", env.owner)
+		if err != nil {
+			t.Fatalf("create book %s: %v", title, err)
+		}
+		return book
+	}
+
+	calculus := newBook("Calculus", science.ID, " Beginner,  Exam   Prep", "beginner")
+	newBook("Chemistry", science.ID, "exam prep")
+	newBook("Rome", history.ID, "beginner", "ancient")
+
+	found, _ := env.books.GetBook(t.Context(), calculus.ID)
+	if !slices.Equal(found.Tags, []string{"beginner", "exam prep"}) {
+		t.Fatalf("expected normalized tags, got %v", found.Tags)
+	}
+
+	for _, tc := range []struct {
+		tags, match string
+		want        []string
+	}{
+		{"beginner", "", []string{"Calculus", "Rome"}},
+		{"beginner,exam prep", "", []string{"Calculus"}},
+		{"beginner,exam prep", "all", []string{"Calculus"}},
+		{"BEGINNER, exam prep", "any", []string{"Calculus", "Chemistry", "Rome"}},
+		{"unknown", "any", nil},
+	} {
+		results, total, err := env.books.SearchBooks(t.Context(), &dto.BookFilterRequest{
+			Tags:              tc.tags,
+			Match:             tc.match,
+			PaginationRequest: dto.PaginationRequest{SortBy: "title"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != len(tc.want) || !slices.Equal(titles(results), tc.want) {
+			t.Errorf("tags %q match %q: expected %v, got %v", tc.tags, tc.match, tc.want, titles(results))
+		}
+	}
+
+	_, err := env.books.CreateBook(t.Context(), &dto.CreateBookRequest{
+		Title:      "Too long",
+		CategoryID: science.ID,
+		Tags:       []string{strings.Repeat("x", 51)},
+	}, &models.BookFile{PDFFile: "long.pdf", Format: "pdf"}, nil, "", env.owner)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	tags, err := env.books.GetTags(t.Context(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 || tags[0].Name != "beginner" || tags[0].BookCount != 2 || tags[2].Name != "ancient" {
+		t.Errorf("expected tags by usage, got %+v", tags)
+	}
+
+	// Omitting the tags keeps them, an empty list removes them
+	update := &dto.UpdateBookRequest{Title: "Calculus", Description: "Limits", CategoryID: science.ID}
+	if err := env.books.UpdateBook(t.Context(), calculus.ID, update, "", env.owner); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := env.books.GetBook(t.Context(), calculus.ID); len(found.Tags) != 2 {
+		t.Errorf("expected the tags to be kept, got %v", found.Tags)
+	}
+	update.Tags = &[]string{}
+	if err := env.books.UpdateBook(t.Context(), calculus.ID, update, "", env.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err = env.books.GetTags(t.Context(), "Be", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "beginner" || tags[0].BookCount != 1 {
+		t.Errorf("unexpected autocompletion: %+v", tags)
+	}
+}
+
+func TestSaveAndUnsaveBook(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "History", nil)
+	book := env.createBook(t, "SPQR", category.ID)
+
+	if err := env.books.SaveBook(t.Context(), env.member, book.ID); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	assertStatus(t, env.books.SaveBook(t.Context(), env.member, book.ID), http.StatusConflict)
+
+	saved, err := env.books.GetSavedBooks(t.Context(), env.member, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].ID != book.ID || saved[0].SaveCount != 1 {
+		t.Fatalf("unexpected saved books: %+v", saved)
+	}
+
+	responses, err := env.books.MapBooksForUser(t.Context(), env.member, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !responses[0].UserSaved {
+		t.Error("expected the book to be marked as saved")
+	}
+
+	if err := env.books.UnsaveBook(t.Context(), env.member, book.ID); err != nil {
+		t.Fatalf("unsave book: %v", err)
+	}
+	found, _ := env.books.GetBook(t.Context(), book.ID)
+	if found.SaveCount != 0 {
+		t.Errorf("expected save count 0 after unsave, got %d", found.SaveCount)
+	}
+
+	if err := env.books.SaveBook(t.Context(), env.member, "missing"); err == nil || err.Error() != "book not found" {
+		t.Errorf("expected book not found, got %v", err)
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Travel", nil)
+	book := env.createBook(t, "Around the World", category.ID)
+
+	users := memory.NewUserRepository(env.store)
+	const readers = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, readers)
+	for i := 0; i < readers; i++ {
+		user := &models.User{Email: fmt.Sprintf("reader%d@example.com", i), Role: models.RoleMember}
+		if err := users.Create(t.Context(), user); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			errs <- env.books.SaveBook(t.Context(), userID, book.ID)
+		}(user.ID)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent save: %v", err)
+		}
+	}
+
+	found, _ := env.books.GetBook(t.Context(), book.ID)
+	if found.SaveCount != readers {
+		t.Errorf("expected save count %d, got %d", readers, found.SaveCount)
+	}
+}
+
+func TestUnitOfWorkRollsBack(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Poetry", nil)
+	book := env.createBook(t, "Leaves of Grass", category.ID)
+
+	failure := errors.New("count update failed")
+	err := memory.NewUnitOfWork(env.store).Do(t.Context(), func(repos *repository.Repositories) error {
+		if err := repos.SavedBooks.Create(t.Context(), &models.SavedBook{UserID: env.member, BookID: book.ID}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the error of the unit of work, got %v", err)
+	}
+
+	saved, err := env.books.GetSavedBooks(t.Context(), env.member, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 0 {
+		t.Errorf("expected the save to be rolled back, got %+v", saved)
+	}
+
+	// The rolled back save must not block saving the book again
+	if err := env.books.SaveBook(t.Context(), env.member, book.ID); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	found, _ := env.books.GetBook(t.Context(), book.ID)
+	if found.SaveCount != 1 {
+		t.Errorf("expected save count 1, got %d", found.SaveCount)
+	}
+}
+
+func TestLikeBook(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Art", nil)
+	book := env.createBook(t, "Ways of Seeing", category.ID)
+
+	resp, err := env.books.LikeBook(t.Context(), env.member, book.ID, true)
+	if err != nil {
+		t.Fatalf("like book: %v", err)
+	}
+	if resp.LikeCount != 1 || resp.DislikeCount != 0 || !resp.UserLiked {
+		t.Errorf("unexpected like response: %+v", resp)
+	}
+
+	// Voting again replaces the earlier vote
+	resp, err = env.books.LikeBook(t.Context(), env.member, book.ID, false)
+	if err != nil {
+		t.Fatalf("dislike book: %v", err)
+	}
+	if resp.LikeCount != 0 || resp.DislikeCount != 1 || !resp.UserDisliked {
+		t.Errorf("unexpected dislike response: %+v", resp)
+	}
+
+	if _, err := env.books.LikeBook(t.Context(), env.owner, book.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = env.books.RemoveLike(t.Context(), env.member, book.ID)
+	if err != nil {
+		t.Fatalf("remove like: %v", err)
+	}
+	if resp.LikeCount != 1 || resp.DislikeCount != 0 || resp.UserLiked || resp.UserDisliked {
+		t.Errorf("unexpected response after removing like: %+v", resp)
+	}
+}
+
+func TestCommentThreads(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Drama", nil)
+	book := env.createBook(t, "Hamlet", category.ID)
+
+	root, err := env.books.AddComment(t.Context(), env.member, book.ID, "To be", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := env.books.AddComment(t.Context(), env.owner, book.ID, "or not to be", &root.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := env.books.AddComment(t.Context(), env.member, book.ID, "that is the question", &reply.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.books.AddComment(t.Context(), env.member, book.ID, "too deep", &nested.ID)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	missing := "missing"
+	_, err = env.books.AddComment(t.Context(), env.member, book.ID, "reply", &missing)
+	assertStatus(t, err, http.StatusNotFound)
+	_, err = env.books.AddComment(t.Context(), env.member, "missing", "comment", nil)
+	assertStatus(t, err, http.StatusNotFound)
+	other := env.createBook(t, "Macbeth", category.ID)
+	_, err = env.books.AddComment(t.Context(), env.member, other.ID, "reply", &root.ID)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	list, err := env.books.GetComments(t.Context(), book.ID, 1, 20, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 3 || len(list.Comments) != 1 || len(list.Comments[0].Replies) != 1 {
+		t.Fatalf("unexpected comment tree: %+v", list)
+	}
+
+	// Deleting a comment with replies leaves a tombstone
+	if err := env.books.DeleteComment(t.Context(), env.owner, book.ID, reply.ID); err != nil {
+		t.Fatalf("delete comment: %v", err)
+	}
+	list, _ = env.books.GetComments(t.Context(), book.ID, 1, 20, "", false)
+	if tombstone := list.Comments[0].Replies[0]; !tombstone.IsDeleted || len(tombstone.Replies) != 1 {
+		t.Errorf("expected a tombstone keeping its reply, got %+v", tombstone)
+	}
+	_, err = env.books.AddComment(t.Context(), env.member, book.ID, "reply", &reply.ID)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Removing the last reply also prunes the tombstone above it
+	if err := env.books.DeleteComment(t.Context(), env.member, book.ID, nested.ID); err != nil {
+		t.Fatalf("delete nested comment: %v", err)
+	}
+	list, _ = env.books.GetComments(t.Context(), book.ID, 1, 20, "", false)
+	if len(list.Comments[0].Replies) != 0 {
+		t.Errorf("expected the tombstone to be pruned, got %+v", list.Comments[0].Replies)
+	}
+}
+
+func TestUpdateCommentOnlyByAuthor(t *testing.T) {
+	env := newBookTestEnv(t)
+	category := env.createCategory(t, "Essays", nil)
+	book := env.createBook(t, "Essais", category.ID)
+
+	comment, err := env.books.AddComment(t.Context(), env.member, book.ID, "first draft", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.books.UpdateComment(t.Context(), env.owner, book.ID, comment.ID, "hijacked")
+	assertStatus(t, err, http.StatusForbidden)
+
+	updated, err := env.books.UpdateComment(t.Context(), env.member, book.ID, comment.ID, "final version")
+	if err != nil {
+		t.Fatalf("update comment: %v", err)
+	}
+	if updated.Content != "final version" {
+		t.Errorf("expected updated content, got %q", updated.Content)
+	}
+}
+
+func TestCategoryHierarchy(t *testing.T) {
+	env := newBookTestEnv(t)
+	parent := env.createCategory(t, "Non-fiction", nil)
+	child := env.createCategory(t, "Biography", &parent.ID)
+
+	_, err := env.books.CreateCategory(t.Context(), &dto.CreateCategoryRequest{Name: "Biography"})
+	assertStatus(t, err, http.StatusConflict)
+
+	// A category cannot become a child of its own descendant
+	_, err = env.books.UpdateCategory(t.Context(), parent.ID, &dto.UpdateCategoryRequest{
+		Name:     parent.Name,
+		ParentID: dto.OptionalString{Set: true, Value: &child.ID},
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Leaving parent_id out keeps the parent, an explicit null detaches the category
+	for _, tc := range []struct {
+		body       string
+		wantParent *string
+	}{
+		{`{"name": "Biographies"}`, &parent.ID},
+		{`{"name": "Biographies", "parent_id": null}`, nil},
+		{`{"name": "Biographies", "parent_id": "` + parent.ID + `"}`, &parent.ID},
+		{`{"name": "Biographies", "parent_id": ""}`, nil},
+	} {
+		var req dto.UpdateCategoryRequest
+		if err := json.Unmarshal([]byte(tc.body), &req); err != nil {
+			t.Fatal(err)
+		}
+		updated, err := env.books.UpdateCategory(t.Context(), child.ID, &req)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.body, err)
+		}
+		if (updated.ParentID == nil) != (tc.wantParent == nil) || updated.ParentID != nil && *updated.ParentID != *tc.wantParent {
+			t.Errorf("%s: expected parent %v, got %v", tc.body, tc.wantParent, updated.ParentID)
+		}
+	}
+	if _, err := env.books.UpdateCategory(t.Context(), child.ID, &dto.UpdateCategoryRequest{
+		Name:     "Biography",
+		ParentID: dto.OptionalString{Set: true, Value: &parent.ID},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	env.createBook(t, "Steve Jobs", child.ID)
+
+	tree, err := env.books.GetCategoryTree(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 1 || tree[0].TotalBookCount != 1 || len(tree[0].Children) != 1 {
+		t.Fatalf("unexpected category tree: %+v", tree)
+	}
+
+	assertStatus(t, env.books.DeleteCategory(t.Context(), parent.ID), http.StatusConflict)
+}
+
+func titles(books []*models.BookWithCategory) []string {
+	result := make([]string, len(books))
+	for i, book := range books {
+		result[i] = book.Title
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"library-project/internal/dto"
+	"library-project/internal/repository"
+	"library-project/internal/utils"
+)
+
+const dashboardBookLimit = 5
+
+const (
+	defaultStatisticsDays = 30
+	maxStatisticsDays     = 365
+)
+
+type StatisticsService struct {
+	statsRepo repository.StatisticsRepository
+	bookRepo  repository.BookRepository
+}
+
+func NewStatisticsService(
+	statsRepo repository.StatisticsRepository,
+	bookRepo repository.BookRepository,
+) *StatisticsService {
+	return &StatisticsService{
+		statsRepo: statsRepo,
+		bookRepo:  bookRepo,
+	}
+}
+
+func (s *StatisticsService) GetDashboard(ctx context.Context) (*dto.DashboardResponse, error) {
+	totals, err := s.statsRepo.GetTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	popular, err := s.bookRepo.FindAllPaginated(ctx, dashboardBookLimit, 0, repository.BookSort{Field: "save_count", Order: "desc"})
+	if err != nil {
+		return nil, err
+	}
+
+	recent, err := s.bookRepo.FindAllPaginated(ctx, dashboardBookLimit, 0, repository.BookSort{Field: "created_at", Order: "desc"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DashboardResponse{
+		TotalBooks:      totals.Books,
+		TotalCategories: totals.Categories,
+		TotalSaves:      totals.Saves,
+		TotalComments:   totals.Comments,
+		PopularBooks:    utils.MapBooksToResponse(popular),
+		RecentBooks:     utils.MapBooksToResponse(recent),
+	}, nil
+}
+
+func (s *StatisticsService) GetBookStatistics(ctx context.Context, bookID, ownerID string, req *dto.StatisticsRequest) (*dto.BookStatisticsResponse, error) {
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, utils.NewInternalServerError("failed to find book", err)
+	}
+	if book == nil {
+		return nil, utils.NewNotFoundError("book")
+	}
+	if book.OwnerID != ownerID {
+		return nil, utils.NewForbiddenError("only the owner of a book can see its statistics")
+	}
+
+	interval := req.Interval
+	if interval == "" {
+		interval = "day"
+	}
+	if interval != "day" && interval != "week" {
+		return nil, utils.NewValidationError("interval must be day or week")
+	}
+	days := req.Days
+	if days == 0 {
+		days = defaultStatisticsDays
+	}
+	if days < 1 || days > maxStatisticsDays {
+		return nil, utils.NewValidationError(fmt.Sprintf("days must be between 1 and %d", maxStatisticsDays))
+	}
+
+	comments, err := s.statsRepo.CountCommentsByBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := s.statsRepo.CountViewsByBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.statsRepo.GetBookActivity(ctx, bookID, interval, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BookStatisticsResponse{
+		BookID:    book.ID,
+		Views:     views,
+		Saves:     book.SaveCount,
+		Likes:     book.LikeCount,
+		Dislikes:  book.DislikeCount,
+		Comments:  comments,
+		Downloads: book.DownloadCount,
+		Interval:  interval,
+		Timeline:  utils.MapActivityBucketsToResponse(buckets),
+	}, nil
+}
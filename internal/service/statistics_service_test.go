@@ -0,0 +1,157 @@
+package service
+
+import (
+	"library-project/internal/dto"
+	"library-project/internal/repository/memory"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newStatisticsService(env *bookTestEnv) *StatisticsService {
+	return NewStatisticsService(memory.NewStatisticsRepository(env.store), memory.NewBookRepository(env.store))
+}
+
+func TestDashboardExcludesDeletedBooks(t *testing.T) {
+	env := newBookTestEnv(t)
+	stats := newStatisticsService(env)
+	category := env.createCategory(t, "Science", nil)
+	kept := env.createBook(t, "Cosmos", category.ID)
+	trashed := env.createBook(t, "Pale Blue Dot", category.ID)
+
+	for _, book := range []string{kept.ID, trashed.ID} {
+		if err := env.books.SaveBook(t.Context(), env.member, book); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := env.books.AddComment(t.Context(), env.member, book, "Great read", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dashboard, err := stats.GetDashboard(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.TotalBooks != 2 || dashboard.TotalSaves != 2 || dashboard.TotalComments != 2 {
+		t.Fatalf("unexpected totals before delete: %+v", dashboard)
+	}
+
+	if err := env.books.DeleteBook(t.Context(), trashed.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	dashboard, err = stats.GetDashboard(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.TotalBooks != 1 || dashboard.TotalCategories != 1 {
+		t.Errorf("expected 1 book in 1 category, got %d in %d", dashboard.TotalBooks, dashboard.TotalCategories)
+	}
+	if dashboard.TotalSaves != 1 || dashboard.TotalComments != 1 {
+		t.Errorf("expected the saves and comments of the deleted book to be left out, got %d saves and %d comments",
+			dashboard.TotalSaves, dashboard.TotalComments)
+	}
+	for _, books := range [][]dto.BookResponse{dashboard.PopularBooks, dashboard.RecentBooks} {
+		if len(books) != 1 || books[0].ID != kept.ID {
+			t.Errorf("expected only %s to be listed, got %+v", kept.Title, books)
+		}
+	}
+}
+
+func TestBookStatisticsTimeline(t *testing.T) {
+	env := newBookTestEnv(t)
+	stats := newStatisticsService(env)
+	category := env.createCategory(t, "Travel", nil)
+	book := env.createBook(t, "In Patagonia", category.ID)
+
+	if err := env.books.RecordView(t.Context(), env.member, book.ID, "127.0.0.1", "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.books.SaveBook(t.Context(), env.member, book.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.books.LikeBook(t.Context(), env.member, book.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		req      dto.StatisticsRequest
+		interval string
+		first    time.Time
+		step     int
+	}{
+		{"defaults", dto.StatisticsRequest{}, "day", today.AddDate(0, 0, -29), 1},
+		{"single day", dto.StatisticsRequest{Interval: "day", Days: 1}, "day", today, 1},
+		{"week", dto.StatisticsRequest{Interval: "week", Days: 7}, "week", weekStart(today.AddDate(0, 0, -6)), 7},
+		{"weeks", dto.StatisticsRequest{Interval: "week", Days: 30}, "week", weekStart(today.AddDate(0, 0, -29)), 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statistics, err := stats.GetBookStatistics(t.Context(), book.ID, env.owner, &tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statistics.Interval != tt.interval {
+				t.Errorf("expected interval %q, got %q", tt.interval, statistics.Interval)
+			}
+
+			// The timeline has one bucket per period up to the current one, without gaps
+			timeline := statistics.Timeline
+			if len(timeline) == 0 || !timeline[0].Period.Equal(tt.first) {
+				t.Fatalf("expected the timeline to start at %s, got %+v", tt.first, timeline)
+			}
+			for i := 1; i < len(timeline); i++ {
+				if want := timeline[i-1].Period.AddDate(0, 0, tt.step); !timeline[i].Period.Equal(want) {
+					t.Fatalf("expected bucket %d to start at %s, got %s", i, want, timeline[i].Period)
+				}
+			}
+
+			last := timeline[len(timeline)-1]
+			if last.Period.After(today) || last.Period.AddDate(0, 0, tt.step).Compare(today) <= 0 {
+				t.Fatalf("expected the last bucket to cover today, got %s", last.Period)
+			}
+			if last.Views != 1 || last.Saves != 1 || last.Likes != 0 || last.Dislikes != 1 {
+				t.Errorf("expected today's activity in the last bucket, got %+v", last)
+			}
+		})
+	}
+}
+
+func TestBookStatisticsValidation(t *testing.T) {
+	env := newBookTestEnv(t)
+	stats := newStatisticsService(env)
+	category := env.createCategory(t, "Art", nil)
+	book := env.createBook(t, "Ways of Seeing", category.ID)
+
+	for _, req := range []dto.StatisticsRequest{
+		{Interval: "month"},
+		{Interval: "hour", Days: 1},
+		{Days: -1},
+		{Days: maxStatisticsDays + 1},
+	} {
+		_, err := stats.GetBookStatistics(t.Context(), book.ID, env.owner, &req)
+		assertStatus(t, err, http.StatusBadRequest)
+	}
+	if _, err := stats.GetBookStatistics(t.Context(), book.ID, env.owner, &dto.StatisticsRequest{Days: maxStatisticsDays}); err != nil {
+		t.Errorf("expected %d days to be accepted, got %v", maxStatisticsDays, err)
+	}
+
+	_, err := stats.GetBookStatistics(t.Context(), book.ID, env.member, &dto.StatisticsRequest{})
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = stats.GetBookStatistics(t.Context(), "missing", env.owner, &dto.StatisticsRequest{})
+	assertStatus(t, err, http.StatusNotFound)
+
+	if err := env.books.DeleteBook(t.Context(), book.ID, env.owner); err != nil {
+		t.Fatal(err)
+	}
+	_, err = stats.GetBookStatistics(t.Context(), book.ID, env.owner, &dto.StatisticsRequest{})
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ObjectReader reads an object through ranged requests so that it can be
+// used as an io.ReadSeeker, e.g. with http.ServeContent, whatever the driver.
+// The range starting at the current offset is only opened on the first Read
+// after a Seek.
+type ObjectReader struct {
+	ctx    context.Context
+	files  Storage
+	object *Object
+	offset int64
+	body   io.ReadCloser
+}
+
+// NewObjectReader returns a reader over object. The caller must close it.
+func NewObjectReader(ctx context.Context, files Storage, object *Object) *ObjectReader {
+	return &ObjectReader{ctx: ctx, files: files, object: object}
+}
+
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.object.Size {
+		return 0, io.EOF
+	}
+
+	if r.body == nil {
+		body, err := r.files.GetRange(r.ctx, r.object.Key, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.object.Size
+	default:
+		return 0, errors.New("storage: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("storage: negative position")
+	}
+
+	if offset != r.offset {
+		if err := r.Close(); err != nil {
+			return 0, err
+		}
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+// Close releases the open range, if any. The reader can still be used afterwards.
+func (r *ObjectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
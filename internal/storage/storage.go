@@ -0,0 +1,58 @@
+// Package storage stores the uploaded book files. The local driver keeps
+// them in a directory on disk; the S3 driver keeps them in a bucket of any
+// S3-compatible object store so that several replicas can share them.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"library-project/config"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no object is stored under the key
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrSignedURLNotSupported is returned by drivers that cannot hand out direct links
+	ErrSignedURLNotSupported = errors.New("storage: signed URLs are not supported by this driver")
+)
+
+// Object describes a stored file
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object for reading. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// GetRange opens length bytes of the object starting at offset for
+	// reading; a negative length reads to the end. The caller must close the reader.
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*Object, error)
+	// List returns every stored object
+	List(ctx context.Context) ([]*Object, error)
+	// SignedURL returns a URL that allows downloading the object without
+	// further credentials until expiry has passed
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// New creates the storage driver selected in the upload configuration
+func New(cfg config.UploadConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.Path)
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q (expected local or s3)", cfg.Driver)
+	}
+}
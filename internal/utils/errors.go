@@ -36,6 +36,8 @@ const (
 	ErrCodeInternalServer  = 1006
 	ErrCodeBadRequest      = 1007
 	ErrCodeInvalidInput    = 1008
+	ErrCodeTimeout         = 1009
+	ErrCodeConflict        = 1010
 )
 
 // Common error constructors
@@ -85,6 +87,16 @@ func NewAlreadyExistsError(resource string) *AppError {
 	}
 }
 
+// NewConflictError reports a request that conflicts with the current state of
+// a resource, such as deleting a category that is still in use
+func NewConflictError(message string) *AppError {
+	return &AppError{
+		Code:       ErrCodeConflict,
+		Message:    message,
+		StatusCode: http.StatusConflict,
+	}
+}
+
 func NewInternalServerError(message string, err error) *AppError {
 	if message == "" {
 		message = "internal server error"
@@ -1,7 +1,9 @@
 package utils
 
 import (
+    "crypto/hmac"
     "crypto/rand"
+    "crypto/sha256"
     "encoding/hex"
     "errors"
     "time"
@@ -49,6 +51,54 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
     return nil, errors.New("invalid token")
 }
 
+// DownloadClaims allow one user to download one book without an access token
+type DownloadClaims struct {
+    UserID string `json:"user_id"`
+    BookID string `json:"book_id"`
+    jwt.RegisteredClaims
+}
+
+// downloadKey derives the signing key of download tokens from the JWT secret,
+// so download tokens are not accepted as access tokens and vice versa
+func downloadKey(secret string) []byte {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte("download-link"))
+    return mac.Sum(nil)
+}
+
+func GenerateDownloadToken(userID, bookID, secret string, expiresAt time.Time) (string, error) {
+    claims := DownloadClaims{
+        UserID: userID,
+        BookID: bookID,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ExpiresAt: jwt.NewNumericDate(expiresAt),
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+        },
+    }
+
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+    return token.SignedString(downloadKey(secret))
+}
+
+func ValidateDownloadToken(tokenString, secret string) (*DownloadClaims, error) {
+    token, err := jwt.ParseWithClaims(tokenString, &DownloadClaims{}, func(token *jwt.Token) (interface{}, error) {
+        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+            return nil, errors.New("invalid signing method")
+        }
+        return downloadKey(secret), nil
+    }, jwt.WithExpirationRequired())
+
+    if err != nil {
+        return nil, err
+    }
+
+    if claims, ok := token.Claims.(*DownloadClaims); ok && token.Valid && claims.UserID != "" && claims.BookID != "" {
+        return claims, nil
+    }
+
+    return nil, errors.New("invalid token")
+}
+
 func GenerateRefreshToken() (string, error) {
     bytes := make([]byte, 32)
     if _, err := rand.Read(bytes); err != nil {
@@ -17,73 +17,229 @@ func MapUserToResponse(user *models.User) dto.UserResponse {
 	}
 }
 
-// // MapBookToResponse converts Book model to BookResponse DTO
-// func MapBookToResponse(book *models.BookWithCategory) dto.BookResponse {
-// 	return dto.BookResponse{
-// 		ID:           book.ID,
-// 		Title:        book.Title,
-// 		Description:  book.Description,
-// 		PDFFile:      book.PDFFile,
-// 		CategoryID:   book.CategoryID,
-// 		CategoryName: book.CategoryName,
-// 		OwnerID:      book.OwnerID,
-// 		LikeCount:    book.LikeCount,
-// 		DislikeCount: book.DislikeCount,
-// 		SaveCount:    book.SaveCount,
-// 		CreatedAt:    book.CreatedAt,
-// 		UpdatedAt:    book.UpdatedAt,
-// 	}
-// }
-
-// // MapBooksToResponse converts slice of Book models to BookResponse DTOs
-// func MapBooksToResponse(books []*models.BookWithCategory) []dto.BookResponse {
-// 	responses := make([]dto.BookResponse, len(books))
-// 	for i, book := range books {
-// 		responses[i] = MapBookToResponse(book)
-// 	}
-// 	return responses
-// }
-
-// // MapCategoryToResponse converts Category model to CategoryResponse DTO
-// func MapCategoryToResponse(category *models.Category) dto.CategoryResponse {
-// 	return dto.CategoryResponse{
-// 		ID:          category.ID,
-// 		Name:        category.Name,
-// 		Description: category.Description,
-// 		CreatedAt:   category.CreatedAt,
-// 		UpdatedAt:   category.UpdatedAt,
-// 	}
-// }
-
-// // MapCategoriesToResponse converts slice of Category models to CategoryResponse DTOs
-// func MapCategoriesToResponse(categories []*models.Category) []dto.CategoryResponse {
-// 	responses := make([]dto.CategoryResponse, len(categories))
-// 	for i, cat := range categories {
-// 		responses[i] = MapCategoryToResponse(cat)
-// 	}
-// 	return responses
-// }
-
-// // MapCommentToResponse converts Comment model to CommentResponse DTO
-// func MapCommentToResponse(comment *models.CommentWithUser) dto.CommentResponse {
-// 	return dto.CommentResponse{
-// 		ID:            comment.ID,
-// 		BookID:        comment.BookID,
-// 		UserID:        comment.UserID,
-// 		UserFirstName: comment.UserFirstName,
-// 		UserLastName:  comment.UserLastName,
-// 		Content:       comment.Content,
-// 		ParentID:      comment.ParentID,
-// 		CreatedAt:     comment.CreatedAt,
-// 		UpdatedAt:     comment.UpdatedAt,
-// 	}
-// }
-
-// // MapCommentsToResponse converts slice of Comment models to CommentResponse DTOs
-// func MapCommentsToResponse(comments []*models.CommentWithUser) []dto.CommentResponse {
-// 	responses := make([]dto.CommentResponse, len(comments))
-// 	for i, comment := range comments {
-// 		responses[i] = MapCommentToResponse(comment)
-// 	}
-// 	return responses
-// }
\ No newline at end of file
+// MapBookToResponse converts Book model to BookResponse DTO
+func MapBookToResponse(book *models.BookWithCategory) dto.BookResponse {
+	return dto.BookResponse{
+		ID:                book.ID,
+		Title:             book.Title,
+		Description:       book.Description,
+		PDFFile:           book.PDFFile,
+		Format:            book.Format,
+		CoverURL:          CoverURL(book.ID),
+		HasCover:          book.CoverImage != "",
+		CategoryID:        book.CategoryID,
+		CategoryName:      book.CategoryName,
+		OwnerID:           book.OwnerID,
+		Authors:           MapBookAuthorsToResponse(book.Authors),
+		Tags:              append([]string{}, book.Tags...),
+		Author:            book.Author,
+		Subject:           book.Subject,
+		Keywords:          book.Keywords,
+		PageCount:         book.PageCount,
+		DocumentCreatedAt: book.DocumentCreatedAt,
+		LikeCount:         book.LikeCount,
+		DislikeCount:      book.DislikeCount,
+		SaveCount:         book.SaveCount,
+		DownloadCount:     book.DownloadCount,
+		CreatedAt:         book.CreatedAt,
+		UpdatedAt:         book.UpdatedAt,
+	}
+}
+
+// CoverURL is where the cover of the book is served, a placeholder for
+// books without one
+func CoverURL(bookID string) string {
+	return "/api/v1/books/" + bookID + "/cover"
+}
+
+// MapBooksToResponse converts slice of Book models to BookResponse DTOs
+func MapBooksToResponse(books []*models.BookWithCategory) []dto.BookResponse {
+	responses := make([]dto.BookResponse, len(books))
+	for i, book := range books {
+		responses[i] = MapBookToResponse(book)
+	}
+	return responses
+}
+
+// MapDownloadToResponse converts DownloadWithUser model to DownloadResponse DTO
+func MapDownloadToResponse(download *models.DownloadWithUser) dto.DownloadResponse {
+	return dto.DownloadResponse{
+		ID:            download.ID,
+		BookID:        download.BookID,
+		UserID:        download.UserID,
+		UserEmail:     download.UserEmail,
+		UserFirstName: download.UserFirstName,
+		UserLastName:  download.UserLastName,
+		IPAddress:     download.IPAddress,
+		UserAgent:     download.UserAgent,
+		DownloadedAt:  download.DownloadedAt,
+	}
+}
+
+// MapDownloadsToResponse converts slice of DownloadWithUser models to DownloadResponse DTOs
+func MapDownloadsToResponse(downloads []*models.DownloadWithUser) []dto.DownloadResponse {
+	responses := make([]dto.DownloadResponse, len(downloads))
+	for i, download := range downloads {
+		responses[i] = MapDownloadToResponse(download)
+	}
+	return responses
+}
+
+// MapActivityBucketsToResponse converts slice of ActivityBucket models to ActivityBucketResponse DTOs
+func MapActivityBucketsToResponse(buckets []*models.ActivityBucket) []dto.ActivityBucketResponse {
+	responses := make([]dto.ActivityBucketResponse, len(buckets))
+	for i, bucket := range buckets {
+		responses[i] = dto.ActivityBucketResponse{
+			Period:    bucket.Period,
+			Views:     bucket.Views,
+			Downloads: bucket.Downloads,
+			Saves:     bucket.Saves,
+			Likes:     bucket.Likes,
+			Dislikes:  bucket.Dislikes,
+			Comments:  bucket.Comments,
+		}
+	}
+	return responses
+}
+
+// MapBookFileToResponse converts BookFile model to BookFileResponse DTO
+func MapBookFileToResponse(file *models.BookFile, current bool) dto.BookFileResponse {
+	return dto.BookFileResponse{
+		ID:           file.ID,
+		Version:      file.Version,
+		Format:       file.Format,
+		PageCount:    file.PageCount,
+		UploadedBy:   file.UploadedBy,
+		RestoredFrom: file.RestoredFrom,
+		Current:      current,
+		CreatedAt:    file.CreatedAt,
+	}
+}
+
+// MapBookFilesToResponse converts the versions of a book, newest first, to
+// BookFileResponse DTOs. The newest version is the current file.
+func MapBookFilesToResponse(files []*models.BookFile) []dto.BookFileResponse {
+	responses := make([]dto.BookFileResponse, len(files))
+	for i, file := range files {
+		responses[i] = MapBookFileToResponse(file, i == 0)
+	}
+	return responses
+}
+
+// MapReadingProgressToResponse converts ReadingProgress model to ReadingProgressResponse DTO
+func MapReadingProgressToResponse(progress *models.ReadingProgress) dto.ReadingProgressResponse {
+	return dto.ReadingProgressResponse{
+		BookID:    progress.BookID,
+		Page:      progress.Page,
+		UpdatedAt: progress.UpdatedAt,
+	}
+}
+
+// MapBookAuthorsToResponse converts the authors of a book to AuthorSummary
+// DTOs. Books without authors get an empty list rather than null.
+func MapBookAuthorsToResponse(authors []models.BookAuthor) []dto.AuthorSummary {
+	responses := make([]dto.AuthorSummary, len(authors))
+	for i, author := range authors {
+		responses[i] = dto.AuthorSummary{ID: author.ID, Name: author.Name}
+	}
+	return responses
+}
+
+// MapAuthorToResponse converts Author model to AuthorResponse DTO
+func MapAuthorToResponse(author *models.Author) dto.AuthorResponse {
+	return dto.AuthorResponse{
+		ID:        author.ID,
+		Name:      author.Name,
+		Bio:       author.Bio,
+		CreatedAt: author.CreatedAt,
+		UpdatedAt: author.UpdatedAt,
+	}
+}
+
+// MapAuthorsWithCountToResponse converts slice of AuthorWithBookCount models to AuthorResponse DTOs
+func MapAuthorsWithCountToResponse(authors []*models.AuthorWithBookCount) []dto.AuthorResponse {
+	responses := make([]dto.AuthorResponse, len(authors))
+	for i, author := range authors {
+		responses[i] = MapAuthorToResponse(&author.Author)
+		responses[i].BookCount = author.BookCount
+	}
+	return responses
+}
+
+// MapTagsToResponse converts slice of TagWithBookCount models to TagResponse DTOs
+func MapTagsToResponse(tags []*models.TagWithBookCount) []dto.TagResponse {
+	responses := make([]dto.TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = dto.TagResponse{Name: tag.Name, BookCount: tag.BookCount}
+	}
+	return responses
+}
+
+// MapCategoryToResponse converts Category model to CategoryResponse DTO
+func MapCategoryToResponse(category *models.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+		ParentID:    category.ParentID,
+		CreatedAt:   category.CreatedAt,
+		UpdatedAt:   category.UpdatedAt,
+	}
+}
+
+// MapCategoriesToResponse converts slice of Category models to CategoryResponse DTOs
+func MapCategoriesToResponse(categories []*models.Category) []dto.CategoryResponse {
+	responses := make([]dto.CategoryResponse, len(categories))
+	for i, cat := range categories {
+		responses[i] = MapCategoryToResponse(cat)
+	}
+	return responses
+}
+
+// MapCategoriesWithCountToResponse converts slice of CategoryWithBookCount models to CategoryResponse DTOs
+func MapCategoriesWithCountToResponse(categories []*models.CategoryWithBookCount) []dto.CategoryResponse {
+	responses := make([]dto.CategoryResponse, len(categories))
+	for i, cat := range categories {
+		responses[i] = MapCategoryToResponse(&cat.Category)
+		responses[i].BookCount = cat.BookCount
+	}
+	return responses
+}
+
+// MapCommentToResponse converts Comment model to CommentResponse DTO.
+// The author of a deleted comment is not exposed.
+func MapCommentToResponse(comment *models.CommentWithUser) dto.CommentResponse {
+	response := dto.CommentResponse{
+		ID:            comment.ID,
+		BookID:        comment.BookID,
+		UserID:        comment.UserID,
+		UserFirstName: comment.UserFirstName,
+		UserLastName:  comment.UserLastName,
+		Content:       comment.Content,
+		ParentID:      comment.ParentID,
+		Depth:         comment.Depth,
+		LikeCount:     comment.LikeCount,
+		DislikeCount:  comment.DislikeCount,
+		CreatedAt:     comment.CreatedAt,
+		UpdatedAt:     comment.UpdatedAt,
+	}
+
+	if comment.DeletedAt != nil {
+		response.IsDeleted = true
+		response.UserID = ""
+		response.UserFirstName = ""
+		response.UserLastName = ""
+		response.Content = models.DeletedCommentContent
+	}
+
+	return response
+}
+
+// MapCommentsToResponse converts slice of Comment models to CommentResponse DTOs
+func MapCommentsToResponse(comments []*models.CommentWithUser) []dto.CommentResponse {
+	responses := make([]dto.CommentResponse, len(comments))
+	for i, comment := range comments {
+		responses[i] = MapCommentToResponse(comment)
+	}
+	return responses
+}
@@ -1,14 +1,42 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"library-project/internal/dto"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// StatusClientClosedRequest is the non-standard status (popularised by nginx)
+// recorded for requests the client abandoned before a response was written
+const StatusClientClosedRequest = 499
+
 // HandleError handles AppError and returns appropriate JSON response
 func HandleError(c *gin.Context, err error) {
+	// A cancelled or timed out request context is not a server fault, so it is
+	// reported separately from the errors below
+	if errors.Is(err, context.Canceled) {
+		LogInfo("Request canceled by client", map[string]interface{}{
+			"path": c.Request.URL.Path,
+		})
+		c.AbortWithStatus(StatusClientClosedRequest)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		LogWarning("Request timed out", map[string]interface{}{
+			"path":  c.Request.URL.Path,
+			"error": err.Error(),
+		})
+		c.JSON(http.StatusGatewayTimeout, dto.ErrorResponse{
+			Error:   "request timed out",
+			Code:    ErrCodeTimeout,
+			Message: err.Error(),
+		})
+		return
+	}
+
 	if appErr, ok := err.(*AppError); ok {
 		// Log the error
 		LogError(appErr.Err, appErr.Message, map[string]interface{}{
@@ -2,52 +2,32 @@ package utils
 
 import (
 	"errors"
+	"library-project/internal/format"
 	"mime/multipart"
-	"net/http"
-	"path/filepath"
 	"regexp"
-	"strings"
 	"unicode"
 )
 
-// ValidatePDFFile checks if the uploaded file is a valid PDF
-func ValidatePDFFile(file *multipart.FileHeader, maxSize int64) error {
+// ValidateBookFile checks that the uploaded file is an e-book in one of the
+// supported formats and returns the format
+func ValidateBookFile(file *multipart.FileHeader, maxSize int64) (*format.Format, error) {
 	if file == nil {
-		return errors.New("file is required")
+		return nil, errors.New("file is required")
 	}
 
 	// Check file size
 	if file.Size > maxSize {
-		return errors.New("file size exceeds maximum limit")
+		return nil, errors.New("file size exceeds maximum limit")
 	}
 
-	// Check file extension
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if ext != ".pdf" {
-		return errors.New("only PDF files are allowed")
-	}
-
-	// Check MIME type by reading file header
 	src, err := file.Open()
 	if err != nil {
-		return errors.New("failed to open file")
+		return nil, errors.New("failed to open file")
 	}
 	defer src.Close()
 
-	// Read first 512 bytes to detect MIME type
-	buffer := make([]byte, 512)
-	_, err = src.Read(buffer)
-	if err != nil {
-		return errors.New("failed to read file")
-	}
-
-	// Detect content type
-	contentType := http.DetectContentType(buffer)
-	if contentType != "application/pdf" {
-		return errors.New("invalid file type: file must be a PDF")
-	}
-
-	return nil
+	// Check the extension and that the content matches it
+	return format.Validate(file.Filename, src, file.Size)
 }
 
 // ValidatePassword validates password strength
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS trigger_update_download_count ON downloads;
+DROP FUNCTION IF EXISTS update_book_download_count();
+
+DROP INDEX IF EXISTS idx_books_download_count;
+ALTER TABLE books DROP COLUMN IF EXISTS download_count;
+
+DROP TABLE IF EXISTS downloads;
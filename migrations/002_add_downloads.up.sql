@@ -9,15 +9,15 @@ CREATE TABLE IF NOT EXISTS downloads (
 );
 
 -- Add index for faster queries
-CREATE INDEX idx_downloads_book_id ON downloads(book_id);
-CREATE INDEX idx_downloads_user_id ON downloads(user_id);
-CREATE INDEX idx_downloads_downloaded_at ON downloads(downloaded_at);
+CREATE INDEX IF NOT EXISTS idx_downloads_book_id ON downloads(book_id);
+CREATE INDEX IF NOT EXISTS idx_downloads_user_id ON downloads(user_id);
+CREATE INDEX IF NOT EXISTS idx_downloads_downloaded_at ON downloads(downloaded_at);
 
 -- Add download_count column to books table
 ALTER TABLE books ADD COLUMN IF NOT EXISTS download_count INTEGER DEFAULT 0;
 
 -- Create index on download_count for sorting
-CREATE INDEX idx_books_download_count ON books(download_count DESC);
+CREATE INDEX IF NOT EXISTS idx_books_download_count ON books(download_count DESC);
 
 -- Function to update download count
 CREATE OR REPLACE FUNCTION update_book_download_count()
@@ -0,0 +1,9 @@
+-- Add full-text search support for books
+ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+-- GIN index for fast text search queries
+CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN(search_vector);
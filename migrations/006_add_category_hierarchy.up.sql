@@ -0,0 +1,5 @@
+-- Allow categories to be nested (e.g. Science > Physics > Quantum)
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id VARCHAR(36)
+    REFERENCES categories(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
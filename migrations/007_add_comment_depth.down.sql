@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_comments_book_threads;
+ALTER TABLE comments DROP COLUMN IF EXISTS depth;
@@ -0,0 +1,17 @@
+-- Track the nesting level of comments (0 = top-level) to enforce reply depth limits
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0;
+
+-- Backfill depth for existing replies
+WITH RECURSIVE tree AS (
+    SELECT id, 0 AS depth FROM comments WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, tree.depth + 1 FROM comments c
+    JOIN tree ON c.parent_id = tree.id
+)
+UPDATE comments SET depth = tree.depth
+FROM tree
+WHERE comments.id = tree.id AND comments.depth <> tree.depth;
+
+-- Top-level threads are listed per book, newest first
+CREATE INDEX IF NOT EXISTS idx_comments_book_threads
+    ON comments(book_id, created_at DESC) WHERE parent_id IS NULL;
@@ -0,0 +1,2 @@
+-- Deleted comments that still have replies are kept as "[deleted]" tombstones
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP DEFAULT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_books_deleted_at;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- Deleted books are kept until the retention period has passed so they can be restored
+ALTER TABLE books ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books(deleted_at) WHERE deleted_at IS NOT NULL;
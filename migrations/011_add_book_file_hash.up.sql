@@ -0,0 +1,2 @@
+-- SHA-256 of the book's file, used as its ETag. Empty until computed for files uploaded before this migration.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS file_hash VARCHAR(64) NOT NULL DEFAULT '';
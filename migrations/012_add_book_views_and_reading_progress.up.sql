@@ -0,0 +1,23 @@
+-- Views of books in the web reader, tracked apart from downloads
+CREATE TABLE IF NOT EXISTS book_views (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ip_address VARCHAR(45),
+    user_agent TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_views_book_id ON book_views(book_id, viewed_at);
+CREATE INDEX IF NOT EXISTS idx_book_views_user_id ON book_views(user_id);
+
+-- The last page each user read of a book
+CREATE TABLE IF NOT EXISTS reading_progress (
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    book_id VARCHAR(36) NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    page INTEGER NOT NULL CHECK (page > 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reading_progress_book_id ON reading_progress(book_id);
@@ -0,0 +1,20 @@
+-- Every file a book has had. books.pdf_file and books.file_hash mirror the
+-- latest version.
+CREATE TABLE IF NOT EXISTS book_files (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version > 0),
+    pdf_file VARCHAR(500) NOT NULL,
+    file_hash VARCHAR(64) NOT NULL DEFAULT '',
+    uploaded_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    -- The version a rollback copied the file from
+    restored_from INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (book_id, version)
+);
+
+-- The files uploaded so far become the first version of their book
+INSERT INTO book_files (id, book_id, version, pdf_file, file_hash, uploaded_by, created_at)
+SELECT gen_random_uuid()::text, id, 1, pdf_file, file_hash, owner_id, created_at
+FROM books
+ON CONFLICT (book_id, version) DO NOTHING;
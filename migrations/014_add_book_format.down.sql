@@ -0,0 +1,2 @@
+ALTER TABLE book_files DROP COLUMN IF EXISTS format;
+ALTER TABLE books DROP COLUMN IF EXISTS format;
@@ -0,0 +1,4 @@
+-- The e-book format of the file (pdf, epub, djvu or txt). Everything
+-- uploaded so far is a PDF.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'pdf';
+ALTER TABLE book_files ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'pdf';
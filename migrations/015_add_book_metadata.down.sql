@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_books_page_count;
+ALTER TABLE book_files DROP COLUMN IF EXISTS page_count;
+ALTER TABLE books DROP COLUMN IF EXISTS document_created_at;
+ALTER TABLE books DROP COLUMN IF EXISTS page_count;
+ALTER TABLE books DROP COLUMN IF EXISTS keywords;
+ALTER TABLE books DROP COLUMN IF EXISTS subject;
+ALTER TABLE books DROP COLUMN IF EXISTS author;
@@ -0,0 +1,11 @@
+-- Document metadata read from uploaded PDFs. An unknown page count is NULL.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS author VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS subject TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS keywords TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS page_count INTEGER CHECK (page_count > 0);
+ALTER TABLE books ADD COLUMN IF NOT EXISTS document_created_at TIMESTAMP;
+
+-- Every version of the file has its own page count; books mirror the current one
+ALTER TABLE book_files ADD COLUMN IF NOT EXISTS page_count INTEGER CHECK (page_count > 0);
+
+CREATE INDEX IF NOT EXISTS idx_books_page_count ON books(page_count);
@@ -0,0 +1,2 @@
+-- Storage key prefix of the cover thumbnails; empty when the book has no cover
+ALTER TABLE books ADD COLUMN IF NOT EXISTS cover_image VARCHAR(255) NOT NULL DEFAULT '';
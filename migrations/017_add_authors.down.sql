@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_books_search_vector;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE books ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN(search_vector);
+
+ALTER TABLE books DROP COLUMN IF EXISTS author_names;
+DROP TABLE IF EXISTS book_authors;
+DROP TABLE IF EXISTS authors;
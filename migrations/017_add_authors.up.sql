@@ -0,0 +1,38 @@
+-- The writers of books, linked to them in order of appearance
+CREATE TABLE IF NOT EXISTS authors (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    bio TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS update_authors_updated_at ON authors;
+CREATE TRIGGER update_authors_updated_at
+    BEFORE UPDATE ON authors
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id VARCHAR(36) NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    author_id VARCHAR(36) NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, author_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_authors_author ON book_authors(author_id);
+
+-- Author names copied onto the book, maintained by AuthorRepository, so the
+-- generated search vector can include them
+ALTER TABLE books ADD COLUMN IF NOT EXISTS author_names TEXT NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_books_search_vector;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE books ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(author_names, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN(search_vector);
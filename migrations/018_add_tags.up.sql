@@ -0,0 +1,18 @@
+-- Free-form labels on books, independent of their category. Names are stored
+-- normalized (trimmed, lower case, single spaces) so they are unique as shown.
+CREATE TABLE IF NOT EXISTS tags (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Serves the prefix lookups of tag autocompletion
+CREATE INDEX IF NOT EXISTS idx_tags_name_prefix ON tags(name text_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS book_tags (
+    book_id VARCHAR(36) NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    tag_id VARCHAR(36) NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (book_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_tags_tag ON book_tags(tag_id);
@@ -0,0 +1,45 @@
+-- The author read from the file or typed into the form becomes an author of
+-- the book. Books uploaded before authors existed are linked here, reusing
+-- authors whose name differs only in case.
+CREATE INDEX IF NOT EXISTS idx_authors_name_lower ON authors(LOWER(name));
+
+INSERT INTO authors (id, name)
+SELECT gen_random_uuid()::text, MIN(b.author)
+FROM books b
+WHERE b.author <> ''
+  AND NOT EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM authors a WHERE LOWER(a.name) = LOWER(b.author))
+GROUP BY LOWER(b.author)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO book_authors (book_id, author_id, position)
+SELECT b.id, (
+    SELECT a.id FROM authors a
+    WHERE LOWER(a.name) = LOWER(b.author)
+    ORDER BY a.name = b.author DESC, a.created_at
+    LIMIT 1
+), 0
+FROM books b
+WHERE b.author <> ''
+  AND NOT EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id);
+
+UPDATE books b
+SET author_names = COALESCE((
+    SELECT string_agg(a.name, ' ' ORDER BY ba.position)
+    FROM book_authors ba
+    JOIN authors a ON a.id = ba.author_id
+    WHERE ba.book_id = b.id
+), '');
+
+-- The free-text author stays searchable when it differs from the linked authors
+DROP INDEX IF EXISTS idx_books_search_vector;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE books ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(author, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(author_names, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN(search_vector);
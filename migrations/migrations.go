@@ -0,0 +1,13 @@
+// Package migrations embeds the versioned SQL migrations into the binary.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+// Applied migrations must not be edited, the runner rejects changed
+// checksums; schema changes go into a new version instead.
+//
+// The scripts need PostgreSQL 13 or later, which provides gen_random_uuid
+// without the pgcrypto extension.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS